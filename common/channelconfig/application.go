@@ -61,9 +61,13 @@ func NewApplicationConfig(appGroup *cb.ConfigGroup, mspConfig *MSPConfigHandler)
 	return ac, nil
 }
 
-// Organizations returns a map of org ID to ApplicationOrg
+// Organizations returns a copy of the map of org ID to ApplicationOrg
 func (ac *ApplicationConfig) Organizations() map[string]ApplicationOrg {
-	return ac.applicationOrgs
+	orgs := make(map[string]ApplicationOrg, len(ac.applicationOrgs))
+	for name, org := range ac.applicationOrgs {
+		orgs[name] = org
+	}
+	return orgs
 }
 
 // Capabilities returns a map of capability name to Capability
@@ -61,9 +61,15 @@ func NewApplicationOrgConfig(id string, orgGroup *cb.ConfigGroup, mspConfig *MSP
 	return aoc, nil
 }
 
-// AnchorPeers returns the list of anchor peers of this Organization
+// AnchorPeers returns a copy of the list of anchor peers of this Organization.
+// The anchor peer messages themselves are shared with the config and must not
+// be modified.
 func (aog *ApplicationOrgConfig) AnchorPeers() []*pb.AnchorPeer {
-	return aog.protos.AnchorPeers.AnchorPeers
+	anchorPeers := aog.protos.AnchorPeers.AnchorPeers
+	if anchorPeers == nil {
+		return nil
+	}
+	return append(make([]*pb.AnchorPeer, 0, len(anchorPeers)), anchorPeers...)
 }
 
 func (aoc *ApplicationOrgConfig) Validate() error {
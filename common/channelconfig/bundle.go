@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package channelconfig
 
 import (
+	"github.com/golang/protobuf/proto"
 	cb "github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/common/cauthdsl"
@@ -28,47 +29,103 @@ const RootGroupKey = "Channel"
 // view of the channel configuration.  In particular, for a given bundle reference,
 // the config sequence, the policy manager etc. will always return exactly the
 // same value.  The Bundle structure is immutable and will always be replaced in its
-// entirety, with new backing memory.
+// entirety, with new backing memory.  Slices and maps returned by the config
+// accessors are copies which the caller may modify, while returned proto
+// messages, such as the batch size or anchor peers, are shared with the bundle
+// and must be treated as read-only.
 type Bundle struct {
 	policyManager   policies.Manager
 	channelConfig   *ChannelConfig
 	configtxManager configtx.Validator
+	bccsp           bccsp.BCCSP
+	config          *cb.Config
+
+	// mspConfig, if set, is the config the MSP manager was built from, as it
+	// differs from config for bundles whose MSP was refreshed by RefreshMSP.
+	mspConfig *cb.Config
+
+	// options are the options the bundle was built with, which are applied
+	// again whenever the bundle is rebuilt, e.g. by Clone.
+	options []BundleOption
+
+	configBlockNumber uint64
+	configBlockHash   []byte
+}
+
+// UnknownConfigBlockNumber is returned by ConfigBlockNumber for bundles which
+// were not built from a config block.
+const UnknownConfigBlockNumber = ^uint64(0)
+
+// ConfigBlockNumber returns the number of the config block the bundle was
+// built from via NewBundleFromBlock, or UnknownConfigBlockNumber if it was
+// built from a config or an envelope.
+func (b *Bundle) ConfigBlockNumber() uint64 {
+	if b.configBlockHash == nil {
+		return UnknownConfigBlockNumber
+	}
+	return b.configBlockNumber
 }
 
-// PolicyManager returns the policy manager constructed for this config.
+// ConfigBlockHash returns the header hash of the config block the bundle was
+// built from via NewBundleFromBlock, or nil if it was built from a config or
+// an envelope.
+func (b *Bundle) ConfigBlockHash() []byte {
+	return copyBytes(b.configBlockHash)
+}
+
+// PolicyManager returns the policy manager constructed for this config.  For
+// bundles built WithoutPolicyManager, every policy evaluation fails with
+// ErrPolicyManagerDisabled.
 func (b *Bundle) PolicyManager() policies.Manager {
 	return b.policyManager
 }
 
-// MSPManager returns the MSP manager constructed for this config.
+// MSPManager returns the MSP manager constructed for this config, or nil if
+// the bundle has no channel config.
 func (b *Bundle) MSPManager() msp.MSPManager {
+	if b.channelConfig == nil {
+		return nil
+	}
 	return b.channelConfig.MSPManager()
 }
 
-// ChannelConfig returns the config.Channel for the chain.
+// ChannelConfig returns the config.Channel for the chain.  As the Channel
+// config is not optional, the Resources interface does not report whether it
+// exists; for a bundle without a channel config, such as a partially
+// populated one, a nil interface is returned rather than a nil *ChannelConfig,
+// so that callers can check for it.
 func (b *Bundle) ChannelConfig() Channel {
+	if b.channelConfig == nil {
+		return nil
+	}
 	return b.channelConfig
 }
 
 // OrdererConfig returns the config.Orderer for the channel
 // and whether the Orderer config exists.
 func (b *Bundle) OrdererConfig() (Orderer, bool) {
-	result := b.channelConfig.OrdererConfig()
-	return result, result != nil
+	if b.channelConfig == nil || b.channelConfig.OrdererConfig() == nil {
+		return nil, false
+	}
+	return b.channelConfig.OrdererConfig(), true
 }
 
 // ConsortiumsConfig returns the config.Consortiums for the channel
 // and whether the consortiums config exists.
 func (b *Bundle) ConsortiumsConfig() (Consortiums, bool) {
-	result := b.channelConfig.ConsortiumsConfig()
-	return result, result != nil
+	if b.channelConfig == nil || b.channelConfig.ConsortiumsConfig() == nil {
+		return nil, false
+	}
+	return b.channelConfig.ConsortiumsConfig(), true
 }
 
 // ApplicationConfig returns the configtxapplication.SharedConfig for the channel
 // and whether the Application config exists.
 func (b *Bundle) ApplicationConfig() (Application, bool) {
-	result := b.channelConfig.ApplicationConfig()
-	return result, result != nil
+	if b.channelConfig == nil || b.channelConfig.ApplicationConfig() == nil {
+		return nil, false
+	}
+	return b.channelConfig.ApplicationConfig(), true
 }
 
 // ConfigtxValidator returns the configtx.Validator for the channel.
@@ -153,9 +210,31 @@ func (b *Bundle) ValidateNew(nb Resources) error {
 	return nil
 }
 
+// NewBundleFromBlock wraps the NewBundleFromEnvelope function, extracting the
+// configtx from a config block.  The number and header hash of the block are
+// recorded in the bundle, see ConfigBlockNumber and ConfigBlockHash.
+func NewBundleFromBlock(block *cb.Block, bccsp bccsp.BCCSP, opts ...BundleOption) (*Bundle, error) {
+	if block.GetHeader() == nil {
+		return nil, errors.New("block header cannot be nil")
+	}
+
+	env, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to extract envelope from block")
+	}
+
+	bundle, err := NewBundleFromEnvelope(env, bccsp, opts...)
+	if err != nil {
+		return nil, err
+	}
+	bundle.configBlockNumber = block.Header.Number
+	bundle.configBlockHash = protoutil.BlockHeaderHash(block.Header)
+	return bundle, nil
+}
+
 // NewBundleFromEnvelope wraps the NewBundle function, extracting the needed
 // information from a full configtx
-func NewBundleFromEnvelope(env *cb.Envelope, bccsp bccsp.BCCSP) (*Bundle, error) {
+func NewBundleFromEnvelope(env *cb.Envelope, bccsp bccsp.BCCSP, opts ...BundleOption) (*Bundle, error) {
 	payload, err := protoutil.UnmarshalPayload(env.Payload)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal payload from envelope")
@@ -175,20 +254,101 @@ func NewBundleFromEnvelope(env *cb.Envelope, bccsp bccsp.BCCSP) (*Bundle, error)
 		return nil, errors.Wrap(err, "failed to unmarshal channel header")
 	}
 
-	return NewBundle(chdr.ChannelId, configEnvelope.Config, bccsp)
+	return NewBundle(chdr.ChannelId, configEnvelope.Config, bccsp, opts...)
+}
+
+// BundleOption configures how NewBundle builds a bundle.
+type BundleOption func(opts *bundleOptions)
+
+type bundleOptions struct {
+	mspManagerFactory        func(config *cb.Config) (msp.MSPManager, error)
+	strictUnknownFields      bool
+	withoutPolicyManager     bool
+	validateMSPReferences    bool
+	validatePolicyReferences bool
+	validateOrdererEndpoints bool
+	validateUniqueMSPIDs     bool
 }
 
-// NewBundle creates a new immutable bundle of configuration
-func NewBundle(channelID string, config *cb.Config, bccsp bccsp.BCCSP) (*Bundle, error) {
+func newBundleOptions(opts []BundleOption) *bundleOptions {
+	options := &bundleOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithMSPManagerFactory causes NewBundle to use the MSP manager returned by the
+// factory for the config, rather than the one built from the MSP definitions
+// of the config, e.g. to substitute a stub in tests.  The MSP definitions are
+// still validated, and for bundles built WithMSPReferenceValidation, the orgs
+// of the config must reference MSPs known to the returned manager.
+func WithMSPManagerFactory(factory func(config *cb.Config) (msp.MSPManager, error)) BundleOption {
+	return func(opts *bundleOptions) {
+		opts.mspManagerFactory = factory
+	}
+}
+
+// NewBundle creates a new immutable bundle of configuration.  The references
+// of the config, its orderer endpoints, and the uniqueness of its MSP IDs are
+// only validated for bundles built with the respective option, see
+// WithMSPReferenceValidation, WithPolicyReferenceValidation,
+// WithOrdererEndpointValidation, and WithUniqueMSPIDValidation, and the policy
+// references never for bundles built WithoutPolicyManager.  These checks are
+// not performed by default because configs which were committed before they
+// existed, e.g. with orderer addresses without a port, must keep loading; they
+// are meant for configs which may still be rejected, such as those of
+// proposed config updates.  If the bundle cannot be built, the returned error
+// is a *MalformedConfigError, *UnsupportedCapabilityError, *UnknownMSPError,
+// *DanglingPolicyError, or *DuplicateMSPIDError, depending on the reason, or
+// the error of the MSP manager factory.  If the MSP of an org cannot be set
+// up, the *MalformedConfigError wraps an *MSPSetupError.
+func NewBundle(channelID string, config *cb.Config, bccsp bccsp.BCCSP, opts ...BundleOption) (*Bundle, error) {
 	if err := preValidate(config); err != nil {
 		return nil, err
 	}
 
 	channelConfig, err := NewChannelConfig(config.ChannelGroup, bccsp)
 	if err != nil {
-		return nil, errors.Wrap(err, "initializing channelconfig failed")
+		return nil, malformedChannelConfigError(err, "initializing channelconfig failed")
+	}
+
+	b, err := newBundle(channelID, config, channelConfig, bccsp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newBundleOptions(opts)
+	if options.validateMSPReferences {
+		if err := b.ValidateMSPReferences(); err != nil {
+			return nil, err
+		}
 	}
 
+	if options.validatePolicyReferences && b.hasPolicyManager() {
+		if err := b.ValidatePolicyReferences(); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.validateOrdererEndpoints {
+		if err := b.ValidateOrdererEndpoints(); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.validateUniqueMSPIDs {
+		if err := b.ValidateUniqueMSPIDs(); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// newPolicyProviderMap returns the providers of the policy types the policy
+// managers of bundles support, resolving principals with the MSP manager.
+func newPolicyProviderMap(mspManager msp.MSPManager) map[int32]policies.Provider {
 	policyProviderMap := make(map[int32]policies.Provider)
 	for pType := range cb.Policy_PolicyType_name {
 		rtype := cb.Policy_PolicyType(pType)
@@ -196,51 +356,128 @@ func NewBundle(channelID string, config *cb.Config, bccsp bccsp.BCCSP) (*Bundle,
 		case cb.Policy_UNKNOWN:
 			// Do not register a handler
 		case cb.Policy_SIGNATURE:
-			policyProviderMap[pType] = cauthdsl.NewPolicyProvider(channelConfig.MSPManager())
+			policyProviderMap[pType] = cauthdsl.NewPolicyProvider(mspManager)
 		case cb.Policy_MSP:
 			// Add hook for MSP Handler here
 		}
 	}
+	return policyProviderMap
+}
 
-	policyManager, err := policies.NewManagerImpl(RootGroupKey, policyProviderMap, config.ChannelGroup)
-	if err != nil {
-		return nil, errors.Wrap(err, "initializing policymanager failed")
+// newBundle builds a bundle around the channel config built from the config,
+// without validating the references of the config.
+func newBundle(channelID string, config *cb.Config, channelConfig *ChannelConfig, bccsp bccsp.BCCSP, opts []BundleOption) (*Bundle, error) {
+	options := newBundleOptions(opts)
+	if options.strictUnknownFields {
+		if err := unknownElementsError(config); err != nil {
+			return nil, &MalformedConfigError{Err: err}
+		}
+	}
+
+	var err error
+	if options.mspManagerFactory != nil {
+		if channelConfig.mspManager, err = options.mspManagerFactory(config); err != nil {
+			return nil, errors.WithMessage(err, "MSP manager factory failed")
+		}
+	}
+
+	var policyManager policies.Manager = disabledPolicyManager{}
+	if !options.withoutPolicyManager {
+		if policyManager, err = policies.NewManagerImpl(RootGroupKey, newPolicyProviderMap(channelConfig.MSPManager()), config.ChannelGroup); err != nil {
+			return nil, &MalformedConfigError{Err: errors.Wrap(err, "initializing policymanager failed")}
+		}
 	}
 
 	configtxManager, err := configtx.NewValidatorImpl(channelID, config, RootGroupKey, policyManager)
 	if err != nil {
-		return nil, errors.Wrap(err, "initializing configtx manager failed")
+		return nil, &MalformedConfigError{Err: errors.Wrap(err, "initializing configtx manager failed")}
 	}
 
 	return &Bundle{
 		policyManager:   policyManager,
 		channelConfig:   channelConfig,
 		configtxManager: configtxManager,
+		bccsp:           bccsp,
+		config:          config,
+		options:         opts,
 	}, nil
 }
 
+// channelID returns the ID of the channel the bundle was built for, or the
+// empty string if the bundle was not built from a config.
+func (b *Bundle) channelID() string {
+	if b == nil || b.configtxManager == nil {
+		return ""
+	}
+	return b.configtxManager.ChannelID()
+}
+
+// Clone returns a new bundle built from a deep copy of the config this bundle
+// was built from, with the same options.  The clone has its own channel
+// config, policy manager, and MSP manager and shares no state with the
+// original, so it may be used to speculatively validate a config before it is
+// installed via Update.
+func (b *Bundle) Clone() (*Bundle, error) {
+	if b.config == nil {
+		return nil, errors.New("bundle was not built from a config")
+	}
+
+	config := proto.Clone(b.config).(*cb.Config)
+	return b.rebuild(config)
+}
+
+// rebuild builds a new bundle for the same channel and the same config block
+// as this bundle, with the same options, from the given config.
+func (b *Bundle) rebuild(config *cb.Config) (*Bundle, error) {
+	bundle, err := NewBundle(b.channelID(), config, b.bccsp, b.options...)
+	if err != nil {
+		return nil, err
+	}
+	bundle.configBlockNumber = b.configBlockNumber
+	bundle.configBlockHash = b.configBlockHash
+	return bundle, nil
+}
+
+// ConfigProto returns the config proto from which this bundle was built, or
+// nil if the bundle was not built from a config.  It may be used to compute a
+// config update against a proposed config.  The returned proto is shared with
+// the bundle and must not be modified; use proto.Clone to obtain a copy which
+// may be modified.
+func (b *Bundle) ConfigProto() *cb.Config {
+	return b.config
+}
+
 func preValidate(config *cb.Config) error {
+	if errs := preValidationErrors(config); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// preValidationErrors returns all problems found by preValidate.
+func preValidationErrors(config *cb.Config) []error {
 	if config == nil {
-		return errors.New("channelconfig Config cannot be nil")
+		return []error{&MalformedConfigError{Err: errors.New("channelconfig Config cannot be nil")}}
 	}
 
 	if config.ChannelGroup == nil {
-		return errors.New("config must contain a channel group")
+		return []error{&MalformedConfigError{Err: errors.New("config must contain a channel group")}}
 	}
 
+	var errs []error
 	if og, ok := config.ChannelGroup.Groups[OrdererGroupKey]; ok {
 		if _, ok := og.Values[CapabilitiesKey]; !ok {
 			if _, ok := config.ChannelGroup.Values[CapabilitiesKey]; ok {
-				return errors.New("cannot enable channel capabilities without orderer support first")
+				errs = append(errs, &UnsupportedCapabilityError{Err: errors.New("cannot enable channel capabilities without orderer support first")})
 			}
 
 			if ag, ok := config.ChannelGroup.Groups[ApplicationGroupKey]; ok {
 				if _, ok := ag.Values[CapabilitiesKey]; ok {
-					return errors.New("cannot enable application capabilities without orderer support first")
+					errs = append(errs, &UnsupportedCapabilityError{Err: errors.New("cannot enable application capabilities without orderer support first")})
 				}
 			}
 		}
 	}
 
-	return nil
+	return errs
 }
@@ -324,6 +324,7 @@ func TestPrevalidation(t *testing.T) {
 
 		require.Error(t, err)
 		require.Regexp(t, "channelconfig Config cannot be nil", err.Error())
+		require.IsType(t, &MalformedConfigError{}, err)
 	})
 
 	t.Run("NilChannelGroup", func(t *testing.T) {
@@ -331,6 +332,7 @@ func TestPrevalidation(t *testing.T) {
 
 		require.Error(t, err)
 		require.Regexp(t, "config must contain a channel group", err.Error())
+		require.IsType(t, &MalformedConfigError{}, err)
 	})
 
 	t.Run("BadChannelCapabilities", func(t *testing.T) {
@@ -347,6 +349,7 @@ func TestPrevalidation(t *testing.T) {
 
 		require.Error(t, err)
 		require.Regexp(t, "cannot enable channel capabilities without orderer support first", err.Error())
+		require.IsType(t, &UnsupportedCapabilityError{}, err)
 	})
 
 	t.Run("BadApplicationCapabilities", func(t *testing.T) {
@@ -365,6 +368,7 @@ func TestPrevalidation(t *testing.T) {
 
 		require.Error(t, err)
 		require.Regexp(t, "cannot enable application capabilities without orderer support first", err.Error())
+		require.IsType(t, &UnsupportedCapabilityError{}, err)
 	})
 
 	t.Run("ValidCapabilities", func(t *testing.T) {
@@ -391,3 +395,74 @@ func TestPrevalidation(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestBundleMissingSections(t *testing.T) {
+	t.Run("NoChannelConfig", func(t *testing.T) {
+		b := &Bundle{}
+		require.Nil(t, b.ChannelConfig())
+		require.Nil(t, b.MSPManager())
+
+		oc, ok := b.OrdererConfig()
+		require.False(t, ok)
+		require.Nil(t, oc)
+		consortiums, ok := b.ConsortiumsConfig()
+		require.False(t, ok)
+		require.Nil(t, consortiums)
+		ac, ok := b.ApplicationConfig()
+		require.False(t, ok)
+		require.Nil(t, ac)
+
+		_, ok = b.ChannelCapabilities()
+		require.False(t, ok)
+		_, ok = b.OrdererCapabilities()
+		require.False(t, ok)
+		_, ok = b.ApplicationCapabilities()
+		require.False(t, ok)
+		require.False(t, b.Supports(FeatureACLs))
+		require.False(t, b.IsSystemChannel())
+
+		bs := NewBundleSource(b)
+		require.Nil(t, bs.OrdererEndpoints())
+		_, ok = bs.ConsensusType()
+		require.False(t, ok)
+		_, ok, err := bs.OrdererMSPs()
+		require.False(t, ok)
+		require.NoError(t, err)
+		_, err = bs.AllMSPs()
+		require.EqualError(t, err, "bundle has no MSP manager")
+		_, err = bs.ValidateIdentity([]byte("identity"))
+		require.EqualError(t, err, "bundle has no MSP manager")
+	})
+
+	for _, tc := range []struct {
+		name                          string
+		channelConfig                 *ChannelConfig
+		orderer, consortiums, appOrgs bool
+	}{
+		{name: "NoSections", channelConfig: &ChannelConfig{protos: &ChannelProtos{}}},
+		{name: "OrdererOnly", channelConfig: &ChannelConfig{protos: &ChannelProtos{}, ordererConfig: &OrdererConfig{protos: &OrdererProtos{Capabilities: &cb.Capabilities{}}}}, orderer: true},
+		{name: "ConsortiumsOnly", channelConfig: &ChannelConfig{protos: &ChannelProtos{}, consortiumsConfig: &ConsortiumsConfig{}}, consortiums: true},
+		{name: "ApplicationOnly", channelConfig: &ChannelConfig{protos: &ChannelProtos{}, appConfig: &ApplicationConfig{protos: &ApplicationProtos{Capabilities: &cb.Capabilities{}}}}, appOrgs: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Bundle{channelConfig: tc.channelConfig}
+			require.NotNil(t, b.ChannelConfig())
+
+			oc, ok := b.OrdererConfig()
+			require.Equal(t, tc.orderer, ok)
+			require.Equal(t, tc.orderer, oc != nil)
+			consortiums, ok := b.ConsortiumsConfig()
+			require.Equal(t, tc.consortiums, ok)
+			require.Equal(t, tc.consortiums, consortiums != nil)
+			ac, ok := b.ApplicationConfig()
+			require.Equal(t, tc.appOrgs, ok)
+			require.Equal(t, tc.appOrgs, ac != nil)
+
+			_, ok = b.OrdererCapabilities()
+			require.Equal(t, tc.orderer, ok)
+			_, ok = b.ApplicationCapabilities()
+			require.Equal(t, tc.appOrgs, ok)
+			require.Equal(t, tc.consortiums, b.IsSystemChannel())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var errBundleCacheComputePanicked = errors.New("computation of cached value panicked")
+
+// BundleCache holds a value derived from the bundle of a BundleSource, such
+// as the set of valid endorsers, and recomputes it only once the bundle it
+// was derived from has been superseded, as reported by the bundle token.  The
+// zero value is an empty cache, ready to use.  A BundleCache is meant to be
+// used with a single BundleSource, and is safe for concurrent use.
+type BundleCache struct {
+	mutex sync.Mutex
+	call  *bundleCacheCall
+}
+
+// bundleCacheCall is the computation of the cached value for one generation
+// of a BundleSource.  value and err must only be read once done is closed.
+type bundleCacheCall struct {
+	generation *bundleGeneration
+	done       chan struct{}
+	value      interface{}
+	err        error
+}
+
+// GetOrCompute returns the value cached for the current bundle of the
+// BundleSource, invoking compute with that bundle first if nothing has been
+// cached for it yet.  Concurrent callers for the same bundle share a single
+// invocation of compute and all receive its result.  Errors returned by
+// compute are passed to the callers sharing the invocation but are not
+// cached, so the next call computes the value again.  If no bundle has been
+// stored yet, ErrBundleSourceNotInitialized is returned.
+func (c *BundleCache) GetOrCompute(bs *BundleSource, compute func(*Bundle) (interface{}, error)) (interface{}, error) {
+	current, err := bs.load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	call := c.call
+	if call != nil && call.generation == current {
+		c.mutex.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+
+	call = &bundleCacheCall{
+		generation: current,
+		done:       make(chan struct{}),
+	}
+	c.call = call
+	c.mutex.Unlock()
+
+	c.compute(call, compute)
+	return call.value, call.err
+}
+
+func (c *BundleCache) compute(call *bundleCacheCall, compute func(*Bundle) (interface{}, error)) {
+	defer func() {
+		if call.err != nil {
+			c.mutex.Lock()
+			if c.call == call {
+				c.call = nil
+			}
+			c.mutex.Unlock()
+		}
+		close(call.done)
+	}()
+
+	call.err = errBundleCacheComputePanicked
+	call.value, call.err = compute(call.generation.bundle)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleCache(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(bundle)
+
+	var computations int32
+	compute := func(b *channelconfig.Bundle) (interface{}, error) {
+		atomic.AddInt32(&computations, 1)
+		return b, nil
+	}
+
+	cache := &channelconfig.BundleCache{}
+	for i := 0; i < 2; i++ {
+		value, err := cache.GetOrCompute(bs, compute)
+		require.NoError(t, err)
+		require.True(t, value == bundle)
+		require.Equal(t, int32(1), computations)
+	}
+
+	newBundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs.Update(newBundle)
+	value, err := cache.GetOrCompute(bs, compute)
+	require.NoError(t, err)
+	require.True(t, value == newBundle)
+	require.Equal(t, int32(2), computations)
+
+	t.Run("SingleFlight", func(t *testing.T) {
+		cache := &channelconfig.BundleCache{}
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var computations int32
+		compute := func(b *channelconfig.Bundle) (interface{}, error) {
+			if atomic.AddInt32(&computations, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return "value", nil
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			cache.GetOrCompute(bs, compute)
+		}()
+		<-started
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := cache.GetOrCompute(bs, compute)
+				require.NoError(t, err)
+				require.Equal(t, "value", value)
+			}()
+		}
+		close(release)
+		wg.Wait()
+		<-done
+		require.Equal(t, int32(1), atomic.LoadInt32(&computations))
+	})
+
+	t.Run("ErrorNotCached", func(t *testing.T) {
+		cache := &channelconfig.BundleCache{}
+		_, err := cache.GetOrCompute(bs, func(*channelconfig.Bundle) (interface{}, error) {
+			return nil, errors.New("boom")
+		})
+		require.EqualError(t, err, "boom")
+
+		value, err := cache.GetOrCompute(bs, func(*channelconfig.Bundle) (interface{}, error) {
+			return "value", nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "value", value)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		cache := &channelconfig.BundleCache{}
+		require.Panics(t, func() {
+			cache.GetOrCompute(bs, func(*channelconfig.Bundle) (interface{}, error) {
+				panic("boom")
+			})
+		})
+
+		value, err := cache.GetOrCompute(bs, func(*channelconfig.Bundle) (interface{}, error) {
+			return "value", nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "value", value)
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		cache := &channelconfig.BundleCache{}
+		_, err := cache.GetOrCompute(&channelconfig.BundleSource{}, compute)
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+	})
+}
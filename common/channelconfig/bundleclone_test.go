@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleClone(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+
+	clone, err := bundle.Clone()
+	require.NoError(t, err)
+	require.True(t, clone != bundle)
+	require.True(t, clone.Equals(bundle))
+	require.True(t, clone.PolicyManager() != bundle.PolicyManager())
+	require.True(t, clone.MSPManager() != bundle.MSPManager())
+	require.Equal(t, bundle.ConfigtxValidator().ChannelID(), clone.ConfigtxValidator().ChannelID())
+	require.Equal(t, bundle.ConfigtxValidator().Sequence(), clone.ConfigtxValidator().Sequence())
+
+	// the clone must not share the config proto with the original
+	clone.ConfigtxValidator().ConfigProto().ChannelGroup.Groups["Orderer"].ModPolicy = "Other"
+	require.Equal(t, "Admins", bundle.ConfigtxValidator().ConfigProto().ChannelGroup.Groups["Orderer"].ModPolicy)
+
+	_, err = (&channelconfig.Bundle{}).Clone()
+	require.EqualError(t, err, "bundle was not built from a config")
+}
+
+func TestBundleConfigProto(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	require.True(t, bundle.ConfigProto() == bundle.ConfigtxValidator().ConfigProto())
+	require.Contains(t, bundle.ConfigProto().ChannelGroup.Groups, "Orderer")
+
+	require.Nil(t, (&channelconfig.Bundle{}).ConfigProto())
+}
+
+func TestBundleAccessorsReturnCopies(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.Addresses = []string{"orderer0:7050"}
+	bundle := newTestBundleFromProfile(t, conf)
+
+	cc := bundle.ChannelConfig()
+	cc.OrdererAddresses()[0] = "mutated"
+	require.Equal(t, []string{"orderer0:7050"}, cc.OrdererAddresses())
+
+	oc, ok := bundle.OrdererConfig()
+	require.True(t, ok)
+	delete(oc.Organizations(), "SampleOrg")
+	require.Contains(t, oc.Organizations(), "SampleOrg")
+	oc.Organizations()["SampleOrg"].Endpoints()[0] = "mutated"
+	require.Equal(t, []string{"127.0.0.1:7050"}, oc.Organizations()["SampleOrg"].Endpoints())
+
+	ac, ok := bundle.ApplicationConfig()
+	require.True(t, ok)
+	delete(ac.Organizations(), "SampleOrg")
+	require.Contains(t, ac.Organizations(), "SampleOrg")
+	anchorPeers := ac.Organizations()["SampleOrg"].AnchorPeers()
+	require.Len(t, anchorPeers, 1)
+	anchorPeers[0] = nil
+	require.NotNil(t, ac.Organizations()["SampleOrg"].AnchorPeers()[0])
+
+	consortiums, ok := bundle.ConsortiumsConfig()
+	require.True(t, ok)
+	delete(consortiums.Consortiums(), "SampleConsortium")
+	require.Contains(t, consortiums.Consortiums(), "SampleConsortium")
+	consortium := consortiums.Consortiums()["SampleConsortium"]
+	delete(consortium.Organizations(), "SampleOrg")
+	require.Contains(t, consortium.Organizations(), "SampleOrg")
+}
@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// ValidateConsenters checks that the client and server TLS certificates of
+// every consenter in the etcdraft consensus metadata of the bundle chain to a
+// TLS root CA of one of the channel MSPs, possibly through their TLS
+// intermediate CAs, so that a consenter set with which the cluster cannot form
+// is rejected when the config is applied rather than when the nodes connect.
+// The returned error names the offending consenter by host and port.  As in
+// the etcdraft chain, expired certificates are not rejected.  The check does
+// not apply, and nil is returned, if the bundle has no Orderer config or its
+// consensus type is not etcdraft.  It must not be called on the bundles stored
+// by a BundleSource created WithReducedMSP, as they lack the TLS CAs.
+func (b *Bundle) ValidateConsenters() error {
+	oc, ok := b.OrdererConfig()
+	if !ok || oc.ConsensusType() != "etcdraft" {
+		return nil
+	}
+
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(oc.ConsensusMetadata(), metadata); err != nil {
+		return errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+	}
+
+	opts, err := b.tlsVerifyOptions()
+	if err != nil {
+		return err
+	}
+
+	for _, consenter := range metadata.Consenters {
+		if err := verifyConsenterCert("client", consenter.ClientTlsCert, opts); err != nil {
+			return errors.WithMessagef(err, "consenter %s:%d", consenter.Host, consenter.Port)
+		}
+		if err := verifyConsenterCert("server", consenter.ServerTlsCert, opts); err != nil {
+			return errors.WithMessagef(err, "consenter %s:%d", consenter.Host, consenter.Port)
+		}
+	}
+	return nil
+}
+
+// tlsVerifyOptions returns options verifying TLS certificates against the TLS
+// root and intermediate CAs of all the MSPs of the bundle.
+func (b *Bundle) tlsVerifyOptions() (x509.VerifyOptions, error) {
+	msps, err := bundleMSPs(b)
+	if err != nil {
+		return x509.VerifyOptions{}, err
+	}
+
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for _, mspID := range sortedMSPIDs(msps) {
+		msp := msps[mspID]
+		for _, pemCert := range msp.GetTLSRootCerts() {
+			cert, err := parsePEMCertificate(pemCert)
+			if err != nil {
+				return x509.VerifyOptions{}, errors.WithMessagef(err, "invalid TLS root CA of MSP %s", mspID)
+			}
+			roots.AddCert(cert)
+		}
+		for _, pemCert := range msp.GetTLSIntermediateCerts() {
+			cert, err := parsePEMCertificate(pemCert)
+			if err != nil {
+				return x509.VerifyOptions{}, errors.WithMessagef(err, "invalid TLS intermediate CA of MSP %s", mspID)
+			}
+			intermediates.AddCert(cert)
+		}
+	}
+
+	return x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageClientAuth,
+			x509.ExtKeyUsageServerAuth,
+		},
+	}, nil
+}
+
+func verifyConsenterCert(certType string, pemCert []byte, opts x509.VerifyOptions) error {
+	cert, err := parsePEMCertificate(pemCert)
+	if err != nil {
+		return errors.WithMessagef(err, "invalid TLS %s certificate", certType)
+	}
+	if _, err := cert.Verify(opts); err != nil {
+		if invalid, ok := err.(x509.CertificateInvalidError); ok && invalid.Reason == x509.Expired {
+			return nil
+		}
+		return errors.Wrapf(err, "TLS %s certificate is not issued by a TLS CA of the channel MSPs", certType)
+	}
+	return nil
+}
+
+func parsePEMCertificate(pemCert []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(pemCert)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+	return cert, nil
+}
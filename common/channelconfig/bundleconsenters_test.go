@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleValidateConsenters(t *testing.T) {
+	tlsCA, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	intermediateCA, err := tlsCA.NewIntermediateCA()
+	require.NoError(t, err)
+	foreignCA, err := tlsgen.NewCA()
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "consenters")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeCert := func(name string, cert []byte) []byte {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, cert, 0644))
+		return []byte(path)
+	}
+
+	// raftBundle returns a bundle whose TLS CAs are the ones generated
+	// above, and whose consenters have certificates issued by the given CAs.
+	raftBundle := func(issuers ...tlsgen.CA) *channelconfig.Bundle {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeEtcdRaftProfile, configtest.GetDevConfigDir())
+		for i, consenter := range conf.Orderer.EtcdRaft.Consenters {
+			client, err := issuers[i].NewClientCertKeyPair()
+			require.NoError(t, err)
+			server, err := issuers[i].NewServerCertKeyPair(consenter.Host)
+			require.NoError(t, err)
+			consenter.ClientTlsCert = writeCert(fmt.Sprintf("client-%d.pem", i), client.Cert)
+			consenter.ServerTlsCert = writeCert(fmt.Sprintf("server-%d.pem", i), server.Cert)
+		}
+
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+		mspConfig := fabricMSPConfigs(t, bs.StableBundle().ConfigProto().ChannelGroup)[0]
+		mspConfig.TlsRootCerts = [][]byte{tlsCA.CertBytes()}
+		mspConfig.TlsIntermediateCerts = [][]byte{intermediateCA.CertBytes()}
+		require.NoError(t, bs.RefreshMSP(mspConfig.Name, mspConfig))
+		return bs.StableBundle()
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		bundle := raftBundle(tlsCA, intermediateCA, tlsCA)
+		require.NoError(t, bundle.ValidateConsenters())
+	})
+
+	t.Run("ForeignCA", func(t *testing.T) {
+		bundle := raftBundle(tlsCA, foreignCA, tlsCA)
+		require.EqualError(t, bundle.ValidateConsenters(), "consenter raft1.example.com:7050: TLS client certificate is not issued by a TLS CA of the channel MSPs: x509: certificate signed by unknown authority")
+	})
+
+	t.Run("SampleCA", func(t *testing.T) {
+		err := newTestRaftBundle(t).ValidateConsenters()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "TLS client certificate is not issued by a TLS CA of the channel MSPs")
+	})
+
+	t.Run("NotEtcdraft", func(t *testing.T) {
+		require.NoError(t, newTestBundle(t, genesisconfig.SampleDevModeSoloProfile).ValidateConsenters())
+		require.NoError(t, newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile).ValidateConsenters())
+	})
+}
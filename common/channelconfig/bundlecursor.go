@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import "sync"
+
+// BundleCursor lets consumers which cannot register update listeners, such as
+// ones driven by a periodic tick, poll a BundleSource for new bundles.  It
+// remembers the sequence of the last bundle it returned, and is safe for
+// concurrent use, in which case each new bundle is returned to one caller.
+type BundleCursor struct {
+	source *BundleSource
+
+	mutex    sync.Mutex
+	sequence uint64
+}
+
+// Cursor returns a new cursor over the bundles of this BundleSource.  The
+// first call to Next returns the current bundle.
+func (bs *BundleSource) Cursor() *BundleCursor {
+	return &BundleCursor{source: bs}
+}
+
+// Next returns the current bundle of the BundleSource and true if it has been
+// replaced since the previous call, or nil and false if it has not.  Bundles
+// stored and replaced between two calls are skipped, so Next always returns
+// the newest bundle.  It returns nil and false if no bundle has been stored
+// yet.
+func (c *BundleCursor) Next() (*Bundle, bool) {
+	current, err := c.source.load()
+	if err != nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if current.sequence == c.sequence {
+		return nil, false
+	}
+	c.sequence = current.sequence
+	return current.bundle, true
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleCursor(t *testing.T) {
+	first, second, third := &channelconfig.Bundle{}, &channelconfig.Bundle{}, &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(first)
+	cursor := bs.Cursor()
+
+	bundle, ok := cursor.Next()
+	require.True(t, ok)
+	require.True(t, bundle == first)
+
+	bundle, ok = cursor.Next()
+	require.False(t, ok)
+	require.Nil(t, bundle)
+
+	bs.Update(second)
+	bs.Update(third)
+	bundle, ok = cursor.Next()
+	require.True(t, ok)
+	require.True(t, bundle == third)
+
+	_, ok = cursor.Next()
+	require.False(t, ok)
+
+	// replacing a bundle by the same bundle is an update as well
+	bs.Update(third)
+	bundle, ok = cursor.Next()
+	require.True(t, ok)
+	require.True(t, bundle == third)
+
+	// cursors are independent of each other
+	bundle, ok = bs.Cursor().Next()
+	require.True(t, ok)
+	require.True(t, bundle == third)
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		bs := &channelconfig.BundleSource{}
+		cursor := bs.Cursor()
+		_, ok := cursor.Next()
+		require.False(t, ok)
+
+		bs.Update(first)
+		bundle, ok := cursor.Next()
+		require.True(t, ok)
+		require.True(t, bundle == first)
+	})
+}
@@ -0,0 +1,445 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// ConfigDiff describes which sections of the channel configuration differ
+// between two bundles.  The config sequence number is not considered, as it
+// advances with every config transaction regardless of its content.
+type ConfigDiff struct {
+	// Channel is true if the values, policies, or mod policy of the
+	// channel group itself differ.
+	Channel bool
+
+	// Orderer is true if the Orderer group was added, removed, or modified.
+	Orderer bool
+
+	// Application is true if the Application group was added, removed, or modified.
+	Application bool
+
+	// Consortiums is true if the Consortiums group was added, removed, or modified.
+	Consortiums bool
+
+	// MSPs is true if any MSP definition was added, removed, or modified.
+	MSPs bool
+
+	// Policies is true if any policy anywhere in the config tree was added,
+	// removed, or modified.
+	Policies bool
+}
+
+// Empty returns whether the diff contains no changes.
+func (cd *ConfigDiff) Empty() bool {
+	return len(cd.changedSections()) == 0
+}
+
+// String returns a deterministic summary of the changed sections, suitable
+// for logging.
+func (cd *ConfigDiff) String() string {
+	sections := cd.changedSections()
+	if len(sections) == 0 {
+		return "no changes"
+	}
+	return "changed sections: " + strings.Join(sections, ", ")
+}
+
+func (cd *ConfigDiff) changedSections() []string {
+	var sections []string
+	for _, section := range []struct {
+		name    string
+		changed bool
+	}{
+		{ChannelGroupKey, cd.Channel},
+		{OrdererGroupKey, cd.Orderer},
+		{ApplicationGroupKey, cd.Application},
+		{ConsortiumsGroupKey, cd.Consortiums},
+		{"MSPs", cd.MSPs},
+		{"Policies", cd.Policies},
+	} {
+		if section.changed {
+			sections = append(sections, section.name)
+		}
+	}
+	return sections
+}
+
+// DiffOption restricts which parts of the configuration Diff, Equals, and
+// CompareConfigs consider.
+type DiffOption func(opts *diffOptions)
+
+type diffOptions struct {
+	ignoredSections   map[string]bool
+	ignoreModPolicies bool
+}
+
+// IgnoreSection causes the comparison to disregard the given section, which is
+// one of ChannelGroupKey, OrdererGroupKey, ApplicationGroupKey, or
+// ConsortiumsGroupKey.  The policies and MSP definitions of an ignored group
+// are disregarded as well.  Ignoring ChannelGroupKey disregards the values,
+// policies, and mod policy of the channel group itself, but not its
+// sub-groups.
+func IgnoreSection(section string) DiffOption {
+	return func(opts *diffOptions) {
+		if opts.ignoredSections == nil {
+			opts.ignoredSections = map[string]bool{}
+		}
+		opts.ignoredSections[section] = true
+	}
+}
+
+// IgnoreModPolicies causes the comparison to disregard the mod policies of
+// all groups, values, and policies, so that a change which only affects who
+// may modify an element is not reported.  As such a change bumps the version
+// of the element, the versions of all elements are disregarded as well.
+func IgnoreModPolicies() DiffOption {
+	return func(opts *diffOptions) {
+		opts.ignoreModPolicies = true
+	}
+}
+
+// Equals returns whether this bundle and the other bundle were built from
+// equivalent configuration, i.e. whether replacing one with the other would
+// be a no-op.  With options, only the parts of the configuration they select
+// are compared.
+func (b *Bundle) Equals(other *Bundle, opts ...DiffOption) bool {
+	return b.Diff(other, opts...).Empty()
+}
+
+// Diff returns which sections of the configuration differ between this bundle
+// and the other bundle.  Without options, the entire configuration is
+// compared.  A nil bundle, or one not built from a config, is reported as
+// differing in every section from any bundle but itself, as its configuration
+// is unknown.
+func (b *Bundle) Diff(other *Bundle, opts ...DiffOption) *ConfigDiff {
+	if b == other {
+		return &ConfigDiff{}
+	}
+	if b == nil || other == nil || b.config == nil || other.config == nil {
+		return &ConfigDiff{Channel: true, Orderer: true, Application: true, Consortiums: true, MSPs: true, Policies: true}
+	}
+
+	diff, err := compareConfigs(b.ConfigProto(), other.ConfigProto(), opts)
+	if err != nil {
+		// The bundles were built from these configs, so the MSP definitions
+		// are known to be well formed, still, be conservative.
+		logger.Warningf("Could not compare MSP definitions of bundles: %s", err)
+		diff.MSPs = true
+	}
+	return diff
+}
+
+// CompareConfigs computes which sections differ between two config protos,
+// without building bundles from them, as needed by offline tooling.  It
+// produces the same ConfigDiff as Bundle.Diff does for bundles built from the
+// configs with the same options.  A nil config is treated as an empty one.
+// An error is returned if an MSP definition of either config cannot be
+// unmarshaled.
+func CompareConfigs(a, b *cb.Config, opts ...DiffOption) (*ConfigDiff, error) {
+	diff, err := compareConfigs(a, b, opts)
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// compareConfigs computes the diff between two config protos.  The returned
+// diff is always non-nil, even when an error is returned, in which case the
+// MSPs field is not populated.
+func compareConfigs(a, b *cb.Config, opts []DiffOption) (*ConfigDiff, error) {
+	options := &diffOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var groupA, groupB *cb.ConfigGroup
+	if a != nil {
+		groupA = options.normalize(a.ChannelGroup)
+	}
+	if b != nil {
+		groupB = options.normalize(b.ChannelGroup)
+	}
+
+	diff := &ConfigDiff{
+		Channel:     !channelValuesEqual(groupA, groupB),
+		Orderer:     !configGroupsEqual(subGroup(groupA, OrdererGroupKey), subGroup(groupB, OrdererGroupKey)),
+		Application: !configGroupsEqual(subGroup(groupA, ApplicationGroupKey), subGroup(groupB, ApplicationGroupKey)),
+		Consortiums: !configGroupsEqual(subGroup(groupA, ConsortiumsGroupKey), subGroup(groupB, ConsortiumsGroupKey)),
+		Policies:    !policiesEqual(collectPolicies(groupA), collectPolicies(groupB)),
+	}
+
+	mspsA, err := collectMSPConfigs(groupA)
+	if err != nil {
+		return diff, err
+	}
+	mspsB, err := collectMSPConfigs(groupB)
+	if err != nil {
+		return diff, err
+	}
+	diff.MSPs = !mspConfigsEqual(mspsA, mspsB)
+
+	return diff, nil
+}
+
+// normalize returns the channel group with the elements disregarded by the
+// options removed.  If no element is disregarded, the group itself is
+// returned, otherwise a modified copy.
+func (opts *diffOptions) normalize(channelGroup *cb.ConfigGroup) *cb.ConfigGroup {
+	if channelGroup == nil || (len(opts.ignoredSections) == 0 && !opts.ignoreModPolicies) {
+		return channelGroup
+	}
+
+	group := proto.Clone(channelGroup).(*cb.ConfigGroup)
+	for section := range opts.ignoredSections {
+		if section == ChannelGroupKey {
+			group.Values = nil
+			group.Policies = nil
+			group.ModPolicy = ""
+			continue
+		}
+		delete(group.Groups, section)
+	}
+	if opts.ignoreModPolicies {
+		clearModPoliciesAndVersions(group)
+	}
+	return group
+}
+
+func clearModPoliciesAndVersions(group *cb.ConfigGroup) {
+	group.ModPolicy = ""
+	group.Version = 0
+	for _, value := range group.Values {
+		value.ModPolicy = ""
+		value.Version = 0
+	}
+	for _, policy := range group.Policies {
+		policy.ModPolicy = ""
+		policy.Version = 0
+	}
+	for _, child := range group.Groups {
+		clearModPoliciesAndVersions(child)
+	}
+}
+
+// channelValuesEqual compares the elements of the channel group itself,
+// ignoring its sub-groups and version.
+func channelValuesEqual(a, b *cb.ConfigGroup) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return groupElementsEqual(a, b)
+}
+
+// configGroupsEqual compares two config groups recursively.
+func configGroupsEqual(a, b *cb.ConfigGroup) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Version != b.Version || !groupElementsEqual(a, b) || len(a.Groups) != len(b.Groups) {
+		return false
+	}
+	for name, group := range a.Groups {
+		if !configGroupsEqual(group, b.Groups[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func groupElementsEqual(a, b *cb.ConfigGroup) bool {
+	if a.ModPolicy != b.ModPolicy || len(a.Values) != len(b.Values) || len(a.Policies) != len(b.Policies) {
+		return false
+	}
+	for name, policy := range a.Policies {
+		if !proto.Equal(policy, b.Policies[name]) {
+			return false
+		}
+	}
+	for key, value := range a.Values {
+		if !configValuesEqual(key, value, b.Values[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// configValuesEqual compares two config values by their content rather than
+// their serialization, as the serialization of messages containing maps (such
+// as capabilities or ACLs) is not deterministic.
+func configValuesEqual(key string, a, b *cb.ConfigValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Version != b.Version || a.ModPolicy != b.ModPolicy {
+		return false
+	}
+	if bytes.Equal(a.Value, b.Value) {
+		return true
+	}
+
+	msgA, msgB := newConfigValueMessage(key), newConfigValueMessage(key)
+	if msgA == nil {
+		return false
+	}
+	if proto.Unmarshal(a.Value, msgA) != nil || proto.Unmarshal(b.Value, msgB) != nil {
+		return false
+	}
+	return proto.Equal(msgA, msgB)
+}
+
+// newConfigValueMessage returns an empty instance of the message stored under
+// the given config value key, or nil if the key is not known.
+func newConfigValueMessage(key string) proto.Message {
+	switch key {
+	case HashingAlgorithmKey:
+		return &cb.HashingAlgorithm{}
+	case BlockDataHashingStructureKey:
+		return &cb.BlockDataHashingStructure{}
+	case OrdererAddressesKey, EndpointsKey:
+		return &cb.OrdererAddresses{}
+	case ConsortiumKey:
+		return &cb.Consortium{}
+	case CapabilitiesKey:
+		return &cb.Capabilities{}
+	case ConsensusTypeKey:
+		return &ab.ConsensusType{}
+	case BatchSizeKey:
+		return &ab.BatchSize{}
+	case BatchTimeoutKey:
+		return &ab.BatchTimeout{}
+	case KafkaBrokersKey:
+		return &ab.KafkaBrokers{}
+	case ChannelRestrictionsKey:
+		return &ab.ChannelRestrictions{}
+	case MSPKey:
+		return &mspprotos.MSPConfig{}
+	case AnchorPeersKey:
+		return &pb.AnchorPeers{}
+	case ACLsKey:
+		return &pb.ACLs{}
+	case ChannelCreationPolicyKey:
+		return &cb.Policy{}
+	default:
+		return nil
+	}
+}
+
+func subGroup(group *cb.ConfigGroup, key string) *cb.ConfigGroup {
+	if group == nil {
+		return nil
+	}
+	return group.Groups[key]
+}
+
+// collectPolicies returns every policy in the config tree keyed by its
+// absolute path, e.g. /Channel/Application/Writers.
+func collectPolicies(channelGroup *cb.ConfigGroup) map[string]*cb.ConfigPolicy {
+	result := map[string]*cb.ConfigPolicy{}
+	if channelGroup == nil {
+		return result
+	}
+
+	var walk func(path string, group *cb.ConfigGroup)
+	walk = func(path string, group *cb.ConfigGroup) {
+		for name, policy := range group.Policies {
+			result[path+policies.PathSeparator+name] = policy
+		}
+		for name, child := range group.Groups {
+			walk(path+policies.PathSeparator+name, child)
+		}
+	}
+	walk(policies.PathSeparator+RootGroupKey, channelGroup)
+
+	return result
+}
+
+func policiesEqual(a, b map[string]*cb.ConfigPolicy) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, policy := range a {
+		if !proto.Equal(policy, b[path]) {
+			return false
+		}
+	}
+	return true
+}
+
+// collectMSPConfigs returns every MSP definition in the config tree keyed by
+// its MSP ID.
+func collectMSPConfigs(channelGroup *cb.ConfigGroup) (map[string]*mspprotos.MSPConfig, error) {
+	result := map[string]*mspprotos.MSPConfig{}
+	if channelGroup == nil {
+		return result, nil
+	}
+
+	var walk func(group *cb.ConfigGroup) error
+	walk = func(group *cb.ConfigGroup) error {
+		if value, ok := group.Values[MSPKey]; ok {
+			mspConfig := &mspprotos.MSPConfig{}
+			if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+				return errors.Wrap(err, "failed to unmarshal MSP config")
+			}
+			mspID, err := mspIDFromConfig(mspConfig)
+			if err != nil {
+				return err
+			}
+			result[mspID] = mspConfig
+		}
+		for _, child := range group.Groups {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return result, walk(channelGroup)
+}
+
+func mspIDFromConfig(mspConfig *mspprotos.MSPConfig) (string, error) {
+	switch mspConfig.Type {
+	case int32(msp.FABRIC):
+		fabricConfig := &mspprotos.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal fabric MSP config")
+		}
+		return fabricConfig.Name, nil
+	case int32(msp.IDEMIX):
+		idemixConfig := &mspprotos.IdemixMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, idemixConfig); err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal idemix MSP config")
+		}
+		return idemixConfig.Name, nil
+	default:
+		return "", errors.Errorf("unsupported msp type %d", mspConfig.Type)
+	}
+}
+
+func mspConfigsEqual(a, b map[string]*mspprotos.MSPConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for mspID, mspConfig := range a {
+		if !proto.Equal(mspConfig, b[mspID]) {
+			return false
+		}
+	}
+	return true
+}
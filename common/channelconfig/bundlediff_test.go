@@ -0,0 +1,213 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleDiff(t *testing.T) {
+	base := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+
+	t.Run("Identical", func(t *testing.T) {
+		same := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+		require.True(t, base.Equals(same))
+		diff := base.Diff(same)
+		require.True(t, diff.Empty())
+		require.Equal(t, "no changes", diff.String())
+	})
+
+	t.Run("OrdererValue", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		conf.Orderer.BatchTimeout = 5 * time.Second
+		changed := newTestBundleFromProfile(t, conf)
+
+		require.False(t, base.Equals(changed))
+		require.Equal(t, &channelconfig.ConfigDiff{Orderer: true}, base.Diff(changed))
+		require.Equal(t, "changed sections: Orderer", base.Diff(changed).String())
+	})
+
+	t.Run("ApplicationPolicy", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		conf.Application.Policies["Writers"] = &genesisconfig.Policy{Type: "ImplicitMeta", Rule: "MAJORITY Writers"}
+		changed := newTestBundleFromProfile(t, conf)
+
+		require.Equal(t, &channelconfig.ConfigDiff{Application: true, Policies: true}, base.Diff(changed))
+		require.Equal(t, "changed sections: Application, Policies", base.Diff(changed).String())
+	})
+
+	t.Run("RemovedSection", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		conf.Application = nil
+		changed := newTestBundleFromProfile(t, conf)
+
+		require.Equal(t, &channelconfig.ConfigDiff{Application: true, Policies: true}, base.Diff(changed))
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		everything := &channelconfig.ConfigDiff{Channel: true, Orderer: true, Application: true, Consortiums: true, MSPs: true, Policies: true}
+		require.Equal(t, everything, base.Diff(nil))
+		require.Equal(t, everything, base.Diff(&channelconfig.Bundle{}))
+		require.Equal(t, everything, (&channelconfig.Bundle{}).Diff(&channelconfig.Bundle{}))
+		require.False(t, base.Equals(nil))
+
+		empty := &channelconfig.Bundle{}
+		require.True(t, empty.Equals(empty))
+	})
+
+	t.Run("MSP", func(t *testing.T) {
+		app := newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile)
+		diff := base.Diff(app)
+		require.True(t, diff.Channel)
+		require.True(t, diff.Orderer)
+		require.True(t, diff.Consortiums)
+		require.False(t, diff.MSPs)
+	})
+
+	t.Run("IgnoreSection", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		conf.Orderer.BatchTimeout = 5 * time.Second
+		conf.Orderer.Policies["Writers"] = &genesisconfig.Policy{Type: "ImplicitMeta", Rule: "MAJORITY Writers"}
+		changed := newTestBundleFromProfile(t, conf)
+
+		require.Equal(t, &channelconfig.ConfigDiff{Orderer: true, Policies: true}, base.Diff(changed))
+		require.True(t, base.Equals(changed, channelconfig.IgnoreSection(channelconfig.OrdererGroupKey)))
+		require.False(t, base.Equals(changed, channelconfig.IgnoreSection(channelconfig.ApplicationGroupKey)))
+
+		app := newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile)
+		diff := base.Diff(app, channelconfig.IgnoreSection(channelconfig.ChannelGroupKey), channelconfig.IgnoreSection(channelconfig.OrdererGroupKey))
+		require.False(t, diff.Channel)
+		require.False(t, diff.Orderer)
+		require.True(t, diff.Consortiums)
+	})
+
+	t.Run("IgnoreModPolicies", func(t *testing.T) {
+		config := proto.Clone(base.ConfigProto()).(*cb.Config)
+		application := config.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+		application.ModPolicy = channelconfig.ReadersPolicyKey
+		application.Version++
+		writers := application.Policies[channelconfig.WritersPolicyKey]
+		writers.ModPolicy = channelconfig.ReadersPolicyKey
+		writers.Version++
+		capabilities := config.ChannelGroup.Values[channelconfig.CapabilitiesKey]
+		capabilities.ModPolicy = channelconfig.ReadersPolicyKey
+		capabilities.Version++
+		changed, err := channelconfig.CompareConfigs(base.ConfigProto(), config)
+		require.NoError(t, err)
+		require.Equal(t, &channelconfig.ConfigDiff{Channel: true, Application: true, Policies: true}, changed)
+
+		diff, err := channelconfig.CompareConfigs(base.ConfigProto(), config, channelconfig.IgnoreModPolicies())
+		require.NoError(t, err)
+		require.True(t, diff.Empty())
+		require.Equal(t, channelconfig.AdminsPolicyKey, base.ConfigProto().ChannelGroup.Groups[channelconfig.ApplicationGroupKey].ModPolicy)
+		require.Zero(t, base.ConfigProto().ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Version)
+
+		// changes of the content of an element are still reported
+		config.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.BatchTimeoutKey].Value = protoutil.MarshalOrPanic(&ab.BatchTimeout{Timeout: "5s"})
+		diff, err = channelconfig.CompareConfigs(base.ConfigProto(), config, channelconfig.IgnoreModPolicies())
+		require.NoError(t, err)
+		require.Equal(t, &channelconfig.ConfigDiff{Orderer: true}, diff)
+	})
+}
+
+func TestCompareConfigs(t *testing.T) {
+	base := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Application = nil
+	changed := newTestBundleFromProfile(t, conf)
+
+	diff, err := channelconfig.CompareConfigs(base.ConfigProto(), changed.ConfigProto())
+	require.NoError(t, err)
+	require.Equal(t, base.Diff(changed), diff)
+	require.Equal(t, &channelconfig.ConfigDiff{Application: true, Policies: true}, diff)
+
+	diff, err = channelconfig.CompareConfigs(base.ConfigProto(), base.ConfigProto())
+	require.NoError(t, err)
+	require.True(t, diff.Empty())
+
+	diff, err = channelconfig.CompareConfigs(nil, base.ConfigProto())
+	require.NoError(t, err)
+	require.Equal(t, &channelconfig.ConfigDiff{Channel: true, Orderer: true, Application: true, Consortiums: true, MSPs: true, Policies: true}, diff)
+
+	malformed := proto.Clone(base.ConfigProto()).(*cb.Config)
+	malformed.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Groups["SampleOrg"].Values[channelconfig.MSPKey].Value = []byte("garbage")
+	diff, err = channelconfig.CompareConfigs(base.ConfigProto(), malformed)
+	require.Error(t, err)
+	require.Nil(t, diff)
+}
+
+func TestBundleSourceUpdateIfChanged(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	var updates int
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) { updates++ })
+
+	require.False(t, bs.UpdateIfChanged(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)))
+	require.Equal(t, 0, updates)
+
+	require.True(t, bs.UpdateIfChanged(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile)))
+	require.Equal(t, 1, updates)
+
+	t.Run("WithoutConfig", func(t *testing.T) {
+		empty := &channelconfig.Bundle{}
+		require.True(t, bs.UpdateIfChanged(empty))
+		require.True(t, bs.StableBundle() == empty)
+		require.False(t, bs.UpdateIfChanged(empty))
+
+		other := &channelconfig.Bundle{}
+		require.True(t, bs.UpdateIfChanged(other))
+		require.True(t, bs.StableBundle() == other)
+		require.Equal(t, 3, updates)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		require.True(t, bs.UpdateIfChanged(nil))
+		require.Nil(t, bs.StableBundle())
+		require.Equal(t, 4, updates)
+	})
+}
+
+func TestBundleSourceDryRun(t *testing.T) {
+	current := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(current)
+
+	var listenerCalls int
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+		listenerCalls++
+	})
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.BatchTimeout = 5 * time.Second
+	diff, err := bs.DryRun(newTestBundleFromProfile(t, conf))
+	require.NoError(t, err)
+	require.Equal(t, &channelconfig.ConfigDiff{Orderer: true}, diff)
+
+	diff, err = bs.DryRun(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.NoError(t, err)
+	require.True(t, diff.Empty())
+
+	require.True(t, bs.StableBundle() == current)
+	require.Equal(t, uint64(1), bs.Sequence())
+	require.Zero(t, listenerCalls)
+
+	_, err = bs.DryRun(nil)
+	require.EqualError(t, err, "new bundle cannot be nil")
+
+	_, err = (&channelconfig.BundleSource{}).DryRun(current)
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"encoding/binary"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The message version and epoch of the channel header of an exported
+	// config block; both are fixed for genesis blocks.
+	exportMsgVersion = int32(1)
+	exportEpoch      = 0
+
+	// snapshotVersion is the version of the format produced by
+	// MarshalSnapshot.  It must be increased whenever the format changes.
+	snapshotVersion = byte(1)
+)
+
+// ToConfigBlock returns a genesis block for the given channel which carries a
+// copy of the config this bundle was built from, structured like the blocks
+// produced by the genesis package, so that it may be used to bootstrap a
+// replacement node.  Unlike a genesis block, the config retains its sequence
+// number, so that config updates computed against the live channel remain
+// applicable.  The block is unsigned, and the channel ID must match the one
+// the bundle was built for.
+func (b *Bundle) ToConfigBlock(channelID string) (*cb.Block, error) {
+	if b.config == nil {
+		return nil, errors.New("bundle was not built from a config")
+	}
+	if channelID == "" {
+		return nil, errors.New("channel ID cannot be empty")
+	}
+	if bundleChannelID := b.channelID(); bundleChannelID != channelID {
+		return nil, errors.Errorf("bundle was built for channel %s, not %s", bundleChannelID, channelID)
+	}
+
+	payloadChannelHeader := protoutil.MakeChannelHeader(cb.HeaderType_CONFIG, exportMsgVersion, channelID, exportEpoch)
+	nonce, err := protoutil.CreateNonce()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create nonce")
+	}
+	payloadSignatureHeader := protoutil.MakeSignatureHeader(nil, nonce)
+	protoutil.SetTxID(payloadChannelHeader, payloadSignatureHeader)
+
+	configEnvelope := &cb.ConfigEnvelope{Config: proto.Clone(b.config).(*cb.Config)}
+	payload := &cb.Payload{
+		Header: protoutil.MakePayloadHeader(payloadChannelHeader, payloadSignatureHeader),
+		Data:   protoutil.MarshalOrPanic(configEnvelope),
+	}
+	envelope := &cb.Envelope{Payload: protoutil.MarshalOrPanic(payload)}
+
+	block := protoutil.NewBlock(0, nil)
+	block.Data = &cb.BlockData{Data: [][]byte{protoutil.MarshalOrPanic(envelope)}}
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = protoutil.MarshalOrPanic(&cb.Metadata{
+		Value: protoutil.MarshalOrPanic(&cb.LastConfig{Index: 0}),
+	})
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = protoutil.MarshalOrPanic(&cb.Metadata{
+		Value: protoutil.MarshalOrPanic(&cb.OrdererBlockMetadata{
+			LastConfig: &cb.LastConfig{Index: 0},
+		}),
+	})
+	return block, nil
+}
+
+// MarshalSnapshot serializes the bundle into a compact snapshot from which
+// LoadBundleFromSnapshot rebuilds an equivalent bundle, e.g. to persist the
+// bundles of many channels and skip reading their config blocks on startup.
+// The snapshot holds the channel ID, the config the bundle was built from,
+// and the number and hash of its config block, if known.  Its first byte is
+// the version of the format.  Bundle options, such as an MSP manager factory,
+// are not part of the snapshot.
+func (b *Bundle) MarshalSnapshot() ([]byte, error) {
+	if b.config == nil {
+		return nil, errors.New("bundle was not built from a config")
+	}
+	config := marshalDeterministic(b.config)
+	if config == nil {
+		return nil, errors.New("failed to marshal config")
+	}
+
+	channelID := b.channelID()
+	data := make([]byte, 0, 1+8+2*binary.MaxVarintLen64+len(channelID)+len(b.configBlockHash)+len(config))
+	data = append(data, snapshotVersion)
+	data = appendUint64(data, b.configBlockNumber)
+	data = appendSnapshotField(data, []byte(channelID))
+	data = appendSnapshotField(data, b.configBlockHash)
+	return append(data, config...), nil
+}
+
+// LoadBundleFromSnapshot rebuilds a bundle from a snapshot produced by
+// MarshalSnapshot, using the default crypto provider.  The bundle is built
+// as by NewBundle, so the config is validated again.  An error is returned
+// if the snapshot was produced by an incompatible version of the format.
+func LoadBundleFromSnapshot(data []byte) (*Bundle, error) {
+	if len(data) == 0 {
+		return nil, errors.New("snapshot is empty")
+	}
+	if data[0] != snapshotVersion {
+		return nil, errors.Errorf("unsupported snapshot version %d, expected %d", data[0], snapshotVersion)
+	}
+	data = data[1:]
+
+	if len(data) < 8 {
+		return nil, errors.New("snapshot is truncated")
+	}
+	configBlockNumber := binary.BigEndian.Uint64(data)
+	data = data[8:]
+
+	channelID, data, err := readSnapshotField(data)
+	if err != nil {
+		return nil, err
+	}
+	configBlockHash, data, err := readSnapshotField(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &cb.Config{}
+	if err := proto.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config of snapshot")
+	}
+
+	bundle, err := NewBundle(string(channelID), config, factory.GetDefault())
+	if err != nil {
+		return nil, err
+	}
+	if len(configBlockHash) > 0 {
+		bundle.configBlockNumber = configBlockNumber
+		bundle.configBlockHash = configBlockHash
+	}
+	return bundle, nil
+}
+
+func appendUint64(data []byte, n uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	return append(data, buf[:]...)
+}
+
+func appendSnapshotField(data, field []byte) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	data = append(data, buf[:binary.PutUvarint(buf[:], uint64(len(field)))]...)
+	return append(data, field...)
+}
+
+// readSnapshotField reads a field written by appendSnapshotField and returns
+// it along with the remaining data.
+func readSnapshotField(data []byte) (field, rest []byte, err error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return nil, nil, errors.New("snapshot is truncated")
+	}
+	data = data[n:]
+	return data[:length], data[length:], nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleToConfigBlock(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	config := proto.Clone(bundle.ConfigProto()).(*cb.Config)
+	config.Sequence = 5
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	bundle, err = channelconfig.NewBundle("testchannel", config, cryptoProvider)
+	require.NoError(t, err)
+
+	block, err := bundle.ToConfigBlock("testchannel")
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), block.Header.Number)
+	require.True(t, bytes.Equal(protoutil.BlockDataHash(block.Data), block.Header.DataHash))
+	require.True(t, protoutil.IsConfigBlock(block))
+
+	lastConfig, err := protoutil.GetLastConfigIndexFromBlock(block)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), lastConfig)
+
+	restored, err := channelconfig.NewBundleFromBlock(block, cryptoProvider)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(bundle.ConfigProto(), restored.ConfigProto()))
+	require.Equal(t, uint64(5), restored.ConfigtxValidator().Sequence())
+	require.Equal(t, "testchannel", restored.ConfigtxValidator().ChannelID())
+	require.NoError(t, bundle.ValidateNew(restored))
+
+	_, err = bundle.ToConfigBlock("otherchannel")
+	require.EqualError(t, err, "bundle was built for channel testchannel, not otherchannel")
+
+	_, err = bundle.ToConfigBlock("")
+	require.EqualError(t, err, "channel ID cannot be empty")
+
+	_, err = (&channelconfig.Bundle{}).ToConfigBlock("testchannel")
+	require.EqualError(t, err, "bundle was not built from a config")
+}
+
+func TestBundleSnapshotRoundTrip(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	unnumbered := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	block, err := unnumbered.ToConfigBlock("testchannel")
+	require.NoError(t, err)
+	block.Header.Number = 7
+	numbered, err := channelconfig.NewBundleFromBlock(block, cryptoProvider)
+	require.NoError(t, err)
+
+	for name, bundle := range map[string]*channelconfig.Bundle{"Unnumbered": unnumbered, "Numbered": numbered} {
+		t.Run(name, func(t *testing.T) {
+			data, err := bundle.MarshalSnapshot()
+			require.NoError(t, err)
+			require.Equal(t, byte(1), data[0])
+
+			restored, err := channelconfig.LoadBundleFromSnapshot(data)
+			require.NoError(t, err)
+			require.True(t, proto.Equal(bundle.ConfigProto(), restored.ConfigProto()))
+			require.Equal(t, "testchannel", restored.ConfigtxValidator().ChannelID())
+			require.Equal(t, bundle.ConfigBlockNumber(), restored.ConfigBlockNumber())
+			require.Equal(t, bundle.ConfigBlockHash(), restored.ConfigBlockHash())
+			require.Equal(t, bundle.Fingerprint(), restored.Fingerprint())
+			require.NoError(t, bundle.ValidateNew(restored))
+
+			again, err := restored.MarshalSnapshot()
+			require.NoError(t, err)
+			require.Equal(t, data, again)
+		})
+	}
+	require.Equal(t, uint64(7), numbered.ConfigBlockNumber())
+
+	t.Run("Invalid", func(t *testing.T) {
+		data, err := numbered.MarshalSnapshot()
+		require.NoError(t, err)
+
+		_, err = channelconfig.LoadBundleFromSnapshot(nil)
+		require.EqualError(t, err, "snapshot is empty")
+
+		future := append([]byte{2}, data[1:]...)
+		_, err = channelconfig.LoadBundleFromSnapshot(future)
+		require.EqualError(t, err, "unsupported snapshot version 2, expected 1")
+
+		for _, length := range []int{5, 10, 25} {
+			_, err = channelconfig.LoadBundleFromSnapshot(data[:length])
+			require.EqualError(t, err, "snapshot is truncated")
+		}
+
+		_, err = channelconfig.LoadBundleFromSnapshot(append(data[:len(data):len(data)], 0xff))
+		require.Error(t, err)
+
+		_, err = (&channelconfig.Bundle{}).MarshalSnapshot()
+		require.EqualError(t, err, "bundle was not built from a config")
+	})
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+)
+
+// Fingerprint returns the hex encoded SHA-256 hash of a canonical
+// serialization of the config this bundle was built from, including the MSP
+// definitions it contains.  The serialization visits groups, values, and
+// policies in sorted order and re-encodes known config values
+// deterministically, so bundles built from equivalent configs have the same
+// fingerprint regardless of how the config was encoded.  Like Equals, the
+// fingerprint depends neither on the config sequence number nor on the version
+// of the channel group, so bundles which Equals considers equal have the same
+// fingerprint.
+func (b *Bundle) Fingerprint() string {
+	h := sha256.New()
+	if config := b.ConfigProto(); config != nil && config.ChannelGroup != nil {
+		writeCanonicalGroupElements(h, config.ChannelGroup)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeCanonicalGroup(h hash.Hash, group *cb.ConfigGroup) {
+	writeCanonicalUint64(h, group.Version)
+	writeCanonicalGroupElements(h, group)
+}
+
+// writeCanonicalGroupElements writes everything of the group but its version.
+func writeCanonicalGroupElements(h hash.Hash, group *cb.ConfigGroup) {
+	writeCanonicalBytes(h, []byte(group.ModPolicy))
+
+	valueKeys := make([]string, 0, len(group.Values))
+	for key := range group.Values {
+		valueKeys = append(valueKeys, key)
+	}
+	sort.Strings(valueKeys)
+	writeCanonicalUint64(h, uint64(len(valueKeys)))
+	for _, key := range valueKeys {
+		value := group.Values[key]
+		writeCanonicalBytes(h, []byte(key))
+		writeCanonicalUint64(h, value.Version)
+		writeCanonicalBytes(h, []byte(value.ModPolicy))
+		writeCanonicalBytes(h, canonicalConfigValue(key, value.Value))
+	}
+
+	policyNames := make([]string, 0, len(group.Policies))
+	for name := range group.Policies {
+		policyNames = append(policyNames, name)
+	}
+	sort.Strings(policyNames)
+	writeCanonicalUint64(h, uint64(len(policyNames)))
+	for _, name := range policyNames {
+		writeCanonicalBytes(h, []byte(name))
+		writeCanonicalBytes(h, marshalDeterministic(group.Policies[name]))
+	}
+
+	groupNames := make([]string, 0, len(group.Groups))
+	for name := range group.Groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+	writeCanonicalUint64(h, uint64(len(groupNames)))
+	for _, name := range groupNames {
+		writeCanonicalBytes(h, []byte(name))
+		writeCanonicalGroup(h, group.Groups[name])
+	}
+}
+
+// canonicalConfigValue re-encodes a config value of a known type
+// deterministically.  Values of unknown type, or which cannot be decoded, are
+// returned unchanged.
+func canonicalConfigValue(key string, value []byte) []byte {
+	msg := newConfigValueMessage(key)
+	if msg == nil || proto.Unmarshal(value, msg) != nil {
+		return value
+	}
+	if canonical := marshalDeterministic(msg); canonical != nil {
+		return canonical
+	}
+	return value
+}
+
+// marshalDeterministic marshals the message with map entries sorted by key,
+// returning nil if the message cannot be marshaled.
+func marshalDeterministic(msg proto.Message) []byte {
+	buf := proto.NewBuffer(nil)
+	buf.SetDeterministic(true)
+	if err := buf.Marshal(msg); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func writeCanonicalBytes(h hash.Hash, b []byte) {
+	writeCanonicalUint64(h, uint64(len(b)))
+	h.Write(b)
+}
+
+func writeCanonicalUint64(h hash.Hash, n uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	h.Write(buf[:])
+}
@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleFingerprint(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	fingerprint := bundle.Fingerprint()
+	require.Len(t, fingerprint, 64)
+
+	// capabilities and ACLs are encoded from maps, so independently built
+	// bundles are not guaranteed to serialize identically
+	for i := 0; i < 10; i++ {
+		require.Equal(t, fingerprint, newTestBundle(t, genesisconfig.SampleDevModeSoloProfile).Fingerprint())
+	}
+
+	clone, err := bundle.Clone()
+	require.NoError(t, err)
+	require.Equal(t, fingerprint, clone.Fingerprint())
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.BatchSize.MaxMessageCount = 10
+	require.NotEqual(t, fingerprint, newTestBundleFromProfile(t, conf).Fingerprint())
+
+	require.NotEqual(t, fingerprint, newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile).Fingerprint())
+
+	config := proto.Clone(bundle.ConfigProto()).(*cb.Config)
+	config.ChannelGroup.Version++
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	rootVersionBumped, err := channelconfig.NewBundle("testchannel", config, cryptoProvider)
+	require.NoError(t, err)
+	require.True(t, bundle.Equals(rootVersionBumped))
+	require.Equal(t, fingerprint, rootVersionBumped.Fingerprint())
+
+	require.Len(t, (&channelconfig.Bundle{}).Fingerprint(), 64)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/pkg/errors"
+)
+
+type bundleJSON struct {
+	Channel     *channelJSON              `json:"channel"`
+	Orderer     *ordererJSON              `json:"orderer,omitempty"`
+	Application *applicationJSON          `json:"application,omitempty"`
+	Consortiums map[string]consortiumJSON `json:"consortiums,omitempty"`
+	MSPs        []string                  `json:"msps"`
+	Policies    []string                  `json:"policies"`
+}
+
+type channelJSON struct {
+	HashingAlgorithm               string   `json:"hashing_algorithm"`
+	BlockDataHashingStructureWidth uint32   `json:"block_data_hashing_structure_width"`
+	OrdererAddresses               []string `json:"orderer_addresses,omitempty"`
+	Consortium                     string   `json:"consortium,omitempty"`
+	Capabilities                   []string `json:"capabilities"`
+}
+
+type ordererJSON struct {
+	ConsensusType     string                    `json:"consensus_type"`
+	ConsensusState    string                    `json:"consensus_state"`
+	MaxMessageCount   uint32                    `json:"max_message_count"`
+	AbsoluteMaxBytes  uint32                    `json:"absolute_max_bytes"`
+	PreferredMaxBytes uint32                    `json:"preferred_max_bytes"`
+	BatchTimeout      string                    `json:"batch_timeout"`
+	MaxChannelsCount  uint64                    `json:"max_channels_count"`
+	KafkaBrokers      []string                  `json:"kafka_brokers,omitempty"`
+	Capabilities      []string                  `json:"capabilities"`
+	Organizations     map[string]ordererOrgJSON `json:"organizations"`
+}
+
+type ordererOrgJSON struct {
+	MSPID     string   `json:"msp_id"`
+	Endpoints []string `json:"endpoints,omitempty"`
+}
+
+type applicationJSON struct {
+	Capabilities  []string                      `json:"capabilities"`
+	Organizations map[string]applicationOrgJSON `json:"organizations"`
+}
+
+type applicationOrgJSON struct {
+	MSPID       string   `json:"msp_id"`
+	AnchorPeers []string `json:"anchor_peers,omitempty"`
+}
+
+type consortiumJSON struct {
+	Organizations map[string]orgJSON `json:"organizations"`
+}
+
+type orgJSON struct {
+	MSPID string `json:"msp_id"`
+}
+
+// MarshalJSON renders the effective channel configuration of the bundle as a
+// deterministic JSON document, so that the rendering of two peers running
+// with the same config can be compared.  It contains the orderer, application,
+// consortiums, and channel sections, the IDs of the channel MSPs, and the
+// paths of the channel policies.  No certificates or key material are
+// included.  An error is returned if the bundle has no channel config.
+func (b *Bundle) MarshalJSON() ([]byte, error) {
+	if b.channelConfig == nil {
+		return nil, errors.New("bundle has no channel config")
+	}
+
+	doc := &bundleJSON{
+		Channel:  b.channelJSON(),
+		Policies: sortedKeys(collectPolicies(b.ConfigProto().GetChannelGroup())),
+	}
+
+	msps, err := bundleMSPs(b)
+	if err != nil {
+		return nil, err
+	}
+	doc.MSPs = sortedMSPIDs(msps)
+
+	if oc := b.channelConfig.OrdererConfig(); oc != nil {
+		doc.Orderer = &ordererJSON{
+			ConsensusType:     oc.ConsensusType(),
+			ConsensusState:    oc.ConsensusState().String(),
+			MaxMessageCount:   oc.BatchSize().MaxMessageCount,
+			AbsoluteMaxBytes:  oc.BatchSize().AbsoluteMaxBytes,
+			PreferredMaxBytes: oc.BatchSize().PreferredMaxBytes,
+			BatchTimeout:      oc.BatchTimeout().String(),
+			MaxChannelsCount:  oc.MaxChannelsCount(),
+			KafkaBrokers:      oc.KafkaBrokers(),
+			Capabilities:      capabilityNames(oc.protos.Capabilities),
+			Organizations:     map[string]ordererOrgJSON{},
+		}
+		for orgName, org := range oc.Organizations() {
+			doc.Orderer.Organizations[orgName] = ordererOrgJSON{
+				MSPID:     org.MSPID(),
+				Endpoints: org.Endpoints(),
+			}
+		}
+	}
+
+	if ac := b.channelConfig.ApplicationConfig(); ac != nil {
+		doc.Application = &applicationJSON{
+			Capabilities:  capabilityNames(ac.protos.Capabilities),
+			Organizations: map[string]applicationOrgJSON{},
+		}
+		for orgName, org := range ac.Organizations() {
+			orgDoc := applicationOrgJSON{MSPID: org.MSPID()}
+			for _, anchorPeer := range org.AnchorPeers() {
+				orgDoc.AnchorPeers = append(orgDoc.AnchorPeers, fmt.Sprintf("%s:%d", anchorPeer.Host, anchorPeer.Port))
+			}
+			doc.Application.Organizations[orgName] = orgDoc
+		}
+	}
+
+	if cc := b.channelConfig.ConsortiumsConfig(); cc != nil {
+		doc.Consortiums = map[string]consortiumJSON{}
+		for consortiumName, consortium := range cc.Consortiums() {
+			consortiumDoc := consortiumJSON{Organizations: map[string]orgJSON{}}
+			for orgName, org := range consortium.Organizations() {
+				consortiumDoc.Organizations[orgName] = orgJSON{MSPID: org.MSPID()}
+			}
+			doc.Consortiums[consortiumName] = consortiumDoc
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func (b *Bundle) channelJSON() *channelJSON {
+	protos := b.channelConfig.protos
+	return &channelJSON{
+		HashingAlgorithm:               protos.HashingAlgorithm.GetName(),
+		BlockDataHashingStructureWidth: protos.BlockDataHashingStructure.GetWidth(),
+		OrdererAddresses:               protos.OrdererAddresses.GetAddresses(),
+		Consortium:                     protos.Consortium.GetName(),
+		Capabilities:                   capabilityNames(protos.Capabilities),
+	}
+}
+
+// capabilityNames returns the sorted names of the given capabilities.
+func capabilityNames(capabilities *cb.Capabilities) []string {
+	names := []string{}
+	for name := range capabilities.GetCapabilities() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]*cb.ConfigPolicy) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleMarshalJSON(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+
+	output, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	require.NotContains(t, string(output), "CERTIFICATE")
+
+	otherOutput, err := json.Marshal(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.NoError(t, err)
+	require.Equal(t, output, otherOutput)
+
+	var doc struct {
+		Channel struct {
+			HashingAlgorithm string   `json:"hashing_algorithm"`
+			Capabilities     []string `json:"capabilities"`
+		} `json:"channel"`
+		Orderer struct {
+			ConsensusType string `json:"consensus_type"`
+			BatchTimeout  string `json:"batch_timeout"`
+			Organizations map[string]struct {
+				MSPID     string   `json:"msp_id"`
+				Endpoints []string `json:"endpoints"`
+			} `json:"organizations"`
+		} `json:"orderer"`
+		Application struct {
+			Organizations map[string]struct {
+				MSPID string `json:"msp_id"`
+			} `json:"organizations"`
+		} `json:"application"`
+		Consortiums map[string]interface{} `json:"consortiums"`
+		MSPs        []string               `json:"msps"`
+		Policies    []string               `json:"policies"`
+	}
+	require.NoError(t, json.Unmarshal(output, &doc))
+
+	require.Equal(t, "SHA256", doc.Channel.HashingAlgorithm)
+	require.Equal(t, []string{"V2_0"}, doc.Channel.Capabilities)
+	require.Equal(t, "solo", doc.Orderer.ConsensusType)
+	require.Equal(t, "2s", doc.Orderer.BatchTimeout)
+	require.Equal(t, "SampleOrg", doc.Orderer.Organizations["SampleOrg"].MSPID)
+	require.Equal(t, []string{"127.0.0.1:7050"}, doc.Orderer.Organizations["SampleOrg"].Endpoints)
+	require.Equal(t, "SampleOrg", doc.Application.Organizations["SampleOrg"].MSPID)
+	require.Contains(t, doc.Consortiums, "SampleConsortium")
+	require.Equal(t, []string{"SampleOrg"}, doc.MSPs)
+	require.Contains(t, doc.Policies, "/Channel/Application/SampleOrg/Admins")
+}
+
+func TestBundleMarshalJSONWithoutChannelConfig(t *testing.T) {
+	_, err := (&channelconfig.Bundle{}).MarshalJSON()
+	require.EqualError(t, err, "bundle has no channel config")
+}
@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+)
+
+// WithoutPolicyManager causes NewBundle to skip building the policy tree of
+// the config, for tools which only inspect the sections and MSPs of a config
+// and never evaluate its policies.  The PolicyManager of such a bundle
+// resolves every path and policy name, but every evaluation of a policy fails
+// with ErrPolicyManagerDisabled, and so does the validation of config updates
+// by its ConfigtxValidator.  As the policies are not built, the policy
+// references of the config are not validated either, even if
+// WithPolicyReferenceValidation is given.
+func WithoutPolicyManager() BundleOption {
+	return func(opts *bundleOptions) {
+		opts.withoutPolicyManager = true
+	}
+}
+
+// disabledPolicyManager is the policy manager of bundles built
+// WithoutPolicyManager.
+type disabledPolicyManager struct{}
+
+func (disabledPolicyManager) GetPolicy(id string) (policies.Policy, bool) {
+	return disabledPolicy{}, true
+}
+
+func (m disabledPolicyManager) Manager(path []string) (policies.Manager, bool) {
+	return m, true
+}
+
+type disabledPolicy struct{}
+
+func (disabledPolicy) EvaluateSignedData(signatureSet []*protoutil.SignedData) error {
+	return ErrPolicyManagerDisabled
+}
+
+func (disabledPolicy) EvaluateIdentities(identities []msp.Identity) error {
+	return ErrPolicyManagerDisabled
+}
+
+// hasPolicyManager returns whether the bundle was built with a policy
+// manager, i.e. without the WithoutPolicyManager option.
+func (b *Bundle) hasPolicyManager() bool {
+	_, disabled := b.policyManager.(disabledPolicyManager)
+	return !disabled
+}
@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithoutPolicyManager(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	// A dangling mod policy is not detected, as the policies are not built.
+	cg.Groups[channelconfig.ApplicationGroupKey].ModPolicy = "Missing"
+
+	bundle, err := channelconfig.NewBundle("foo", &cb.Config{ChannelGroup: cg}, cryptoProvider, channelconfig.WithoutPolicyManager())
+	require.NoError(t, err)
+
+	ac, ok := bundle.ApplicationConfig()
+	require.True(t, ok)
+	require.Contains(t, ac.Organizations(), "SampleOrg")
+	msps, err := bundle.MSPManager().GetMSPs()
+	require.NoError(t, err)
+	require.Contains(t, msps, "SampleOrg")
+
+	manager, ok := bundle.PolicyManager().Manager([]string{channelconfig.ApplicationGroupKey})
+	require.True(t, ok)
+	policy, ok := manager.GetPolicy("Writers")
+	require.True(t, ok)
+	require.Equal(t, channelconfig.ErrPolicyManagerDisabled, policy.EvaluateSignedData(nil))
+	require.Equal(t, channelconfig.ErrPolicyManagerDisabled, policy.EvaluateIdentities(nil))
+
+	clone, err := bundle.Clone()
+	require.NoError(t, err)
+	policy, _ = clone.PolicyManager().GetPolicy("/Channel/Readers")
+	require.Equal(t, channelconfig.ErrPolicyManagerDisabled, policy.EvaluateSignedData(nil))
+}
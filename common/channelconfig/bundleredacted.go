@@ -0,0 +1,166 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// RedactedConfig is a view of the channel configuration which is safe to hand
+// to untrusted consumers.  It keeps the org and policy structure of the
+// channel but omits certificates, revocation lists, and any other MSP
+// material.
+type RedactedConfig struct {
+	Organizations           []RedactedOrg    `json:"organizations"`
+	Policies                []RedactedPolicy `json:"policies"`
+	ChannelCapabilities     []string         `json:"channel_capabilities"`
+	OrdererCapabilities     []string         `json:"orderer_capabilities,omitempty"`
+	ApplicationCapabilities []string         `json:"application_capabilities,omitempty"`
+	Batch                   *RedactedBatch   `json:"batch,omitempty"`
+}
+
+// RedactedOrg identifies an org of the channel.  Section is Orderer,
+// Application, or Consortiums/<consortium name>.
+type RedactedOrg struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	MSPID   string `json:"msp_id"`
+}
+
+// RedactedPolicy describes a channel policy by its absolute path, its type,
+// and a rendering of its rule.  Signature principals which carry identity
+// material are rendered by their classification only.
+type RedactedPolicy struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Rule string `json:"rule,omitempty"`
+}
+
+// RedactedBatch holds the block cutting settings of the orderer.
+type RedactedBatch struct {
+	MaxMessageCount   uint32 `json:"max_message_count"`
+	AbsoluteMaxBytes  uint32 `json:"absolute_max_bytes"`
+	PreferredMaxBytes uint32 `json:"preferred_max_bytes"`
+	Timeout           string `json:"timeout"`
+}
+
+// RedactedView returns a redacted view of the configuration of the bundle.
+// Unlike MarshalJSON, the view omits orderer and anchor peer endpoints as well,
+// and is intended for consumers which must not learn more than the IDs and
+// policy structure of the channel.  Organizations are sorted by section and
+// name, and policies by path.  A bundle without a channel config yields an
+// empty view.
+func (b *Bundle) RedactedView() *RedactedConfig {
+	if b.channelConfig == nil {
+		return &RedactedConfig{}
+	}
+
+	view := &RedactedConfig{
+		ChannelCapabilities: capabilityNames(b.channelConfig.protos.Capabilities),
+	}
+
+	if oc := b.channelConfig.OrdererConfig(); oc != nil {
+		view.OrdererCapabilities = capabilityNames(oc.protos.Capabilities)
+		view.Batch = &RedactedBatch{
+			MaxMessageCount:   oc.BatchSize().MaxMessageCount,
+			AbsoluteMaxBytes:  oc.BatchSize().AbsoluteMaxBytes,
+			PreferredMaxBytes: oc.BatchSize().PreferredMaxBytes,
+			Timeout:           oc.BatchTimeout().String(),
+		}
+		for orgName, org := range oc.Organizations() {
+			view.Organizations = append(view.Organizations, RedactedOrg{Section: OrdererGroupKey, Name: orgName, MSPID: org.MSPID()})
+		}
+	}
+
+	if ac := b.channelConfig.ApplicationConfig(); ac != nil {
+		view.ApplicationCapabilities = capabilityNames(ac.protos.Capabilities)
+		for orgName, org := range ac.Organizations() {
+			view.Organizations = append(view.Organizations, RedactedOrg{Section: ApplicationGroupKey, Name: orgName, MSPID: org.MSPID()})
+		}
+	}
+
+	if cc := b.channelConfig.ConsortiumsConfig(); cc != nil {
+		for consortiumName, consortium := range cc.Consortiums() {
+			section := ConsortiumsGroupKey + "/" + consortiumName
+			for orgName, org := range consortium.Organizations() {
+				view.Organizations = append(view.Organizations, RedactedOrg{Section: section, Name: orgName, MSPID: org.MSPID()})
+			}
+		}
+	}
+
+	sort.Slice(view.Organizations, func(i, j int) bool {
+		if view.Organizations[i].Section != view.Organizations[j].Section {
+			return view.Organizations[i].Section < view.Organizations[j].Section
+		}
+		return view.Organizations[i].Name < view.Organizations[j].Name
+	})
+
+	configPolicies := collectPolicies(b.ConfigProto().GetChannelGroup())
+	for _, path := range sortedKeys(configPolicies) {
+		view.Policies = append(view.Policies, redactedPolicy(path, configPolicies[path]))
+	}
+
+	return view
+}
+
+func redactedPolicy(path string, configPolicy *cb.ConfigPolicy) RedactedPolicy {
+	policyType := cb.Policy_PolicyType(configPolicy.GetPolicy().GetType())
+	result := RedactedPolicy{Path: path, Type: policyType.String()}
+
+	switch policyType {
+	case cb.Policy_IMPLICIT_META:
+		definition := &cb.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, definition); err == nil {
+			result.Rule = fmt.Sprintf("%s %s", definition.Rule, definition.SubPolicy)
+		}
+	case cb.Policy_SIGNATURE:
+		envelope := &cb.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, envelope); err == nil {
+			result.Rule = signaturePolicyRule(envelope.Rule, envelope.Identities)
+		}
+	}
+	return result
+}
+
+// signaturePolicyRule renders a signature policy in the syntax of policydsl,
+// e.g. OutOf(1, 'Org1MSP.admin').
+func signaturePolicyRule(rule *cb.SignaturePolicy, identities []*mspprotos.MSPPrincipal) string {
+	switch t := rule.GetType().(type) {
+	case *cb.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || int(t.SignedBy) >= len(identities) {
+			return "?"
+		}
+		return redactedPrincipal(identities[t.SignedBy])
+	case *cb.SignaturePolicy_NOutOf_:
+		rules := make([]string, 0, len(t.NOutOf.Rules))
+		for _, subRule := range t.NOutOf.Rules {
+			rules = append(rules, signaturePolicyRule(subRule, identities))
+		}
+		return fmt.Sprintf("OutOf(%d, %s)", t.NOutOf.N, strings.Join(rules, ", "))
+	default:
+		return "?"
+	}
+}
+
+// redactedPrincipal renders role principals as 'MSPID.role'.  Other principal
+// classifications may embed certificates or their hashes, so only the
+// classification is rendered.
+func redactedPrincipal(principal *mspprotos.MSPPrincipal) string {
+	if principal.PrincipalClassification == mspprotos.MSPPrincipal_ROLE {
+		role := &mspprotos.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err == nil {
+			return fmt.Sprintf("'%s.%s'", role.MspIdentifier, strings.ToLower(role.Role.String()))
+		}
+	}
+	return principal.PrincipalClassification.String()
+}
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleRedactedView(t *testing.T) {
+	view := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile).RedactedView()
+
+	require.Equal(t, []channelconfig.RedactedOrg{
+		{Section: "Application", Name: "SampleOrg", MSPID: "SampleOrg"},
+		{Section: "Consortiums/SampleConsortium", Name: "SampleOrg", MSPID: "SampleOrg"},
+		{Section: "Orderer", Name: "SampleOrg", MSPID: "SampleOrg"},
+	}, view.Organizations)
+	require.Equal(t, []string{"V2_0"}, view.ChannelCapabilities)
+	require.Equal(t, []string{"V2_0"}, view.OrdererCapabilities)
+	require.Equal(t, []string{"V2_0"}, view.ApplicationCapabilities)
+	require.Equal(t, &channelconfig.RedactedBatch{
+		MaxMessageCount:   500,
+		AbsoluteMaxBytes:  10 * 1024 * 1024,
+		PreferredMaxBytes: 2 * 1024 * 1024,
+		Timeout:           "2s",
+	}, view.Batch)
+
+	policies := map[string]channelconfig.RedactedPolicy{}
+	for _, policy := range view.Policies {
+		policies[policy.Path] = policy
+	}
+	require.Equal(t, channelconfig.RedactedPolicy{
+		Path: "/Channel/Application/Writers",
+		Type: "IMPLICIT_META",
+		Rule: "ANY Writers",
+	}, policies["/Channel/Application/Writers"])
+	require.Equal(t, channelconfig.RedactedPolicy{
+		Path: "/Channel/Application/SampleOrg/Readers",
+		Type: "SIGNATURE",
+		Rule: "OutOf(1, 'SampleOrg.member')",
+	}, policies["/Channel/Application/SampleOrg/Readers"])
+
+	output, err := json.Marshal(view)
+	require.NoError(t, err)
+	require.NotContains(t, string(output), "CERTIFICATE")
+	require.NotContains(t, string(output), "127.0.0.1")
+}
+
+func TestBundleRedactedViewWithoutChannelConfig(t *testing.T) {
+	require.Equal(t, &channelconfig.RedactedConfig{}, (&channelconfig.Bundle{}).RedactedView())
+}
@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// BundleSnapshot is an immutable view of a single generation of a
+// BundleSource.  All of its methods are answered from the same bundle, so
+// retrieving several config sections from one snapshot is always consistent,
+// regardless of concurrent calls to Update.
+type BundleSnapshot struct {
+	bundle   *Bundle
+	sequence uint64
+}
+
+// Snapshot returns a view of the current bundle and its sequence number.
+// Like StableBundle, it panics if no bundle has been stored yet.
+func (bs *BundleSource) Snapshot() *BundleSnapshot {
+	bundle, sequence := bs.SequencedBundle()
+	return &BundleSnapshot{
+		bundle:   bundle,
+		sequence: sequence,
+	}
+}
+
+// Bundle returns the bundle this snapshot was taken from.
+func (s *BundleSnapshot) Bundle() *Bundle {
+	return s.bundle
+}
+
+// Sequence returns the BundleSource sequence number of the bundle.
+func (s *BundleSnapshot) Sequence() uint64 {
+	return s.sequence
+}
+
+// PolicyManager returns the policy manager constructed for this config.
+func (s *BundleSnapshot) PolicyManager() policies.Manager {
+	return s.bundle.PolicyManager()
+}
+
+// MSPManager returns the MSP manager constructed for this config.
+func (s *BundleSnapshot) MSPManager() msp.MSPManager {
+	return s.bundle.MSPManager()
+}
+
+// ChannelConfig returns the config.Channel for the chain.
+func (s *BundleSnapshot) ChannelConfig() Channel {
+	return s.bundle.ChannelConfig()
+}
+
+// OrdererConfig returns the config.Orderer for the channel
+// and whether the Orderer config exists.
+func (s *BundleSnapshot) OrdererConfig() (Orderer, bool) {
+	return s.bundle.OrdererConfig()
+}
+
+// ConsortiumsConfig returns the config.Consortiums for the channel
+// and whether the consortiums config exists.
+func (s *BundleSnapshot) ConsortiumsConfig() (Consortiums, bool) {
+	return s.bundle.ConsortiumsConfig()
+}
+
+// ApplicationConfig returns the Application config for the channel
+// and whether the Application config exists.
+func (s *BundleSnapshot) ApplicationConfig() (Application, bool) {
+	return s.bundle.ApplicationConfig()
+}
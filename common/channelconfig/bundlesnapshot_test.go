@@ -0,0 +1,40 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSnapshot(t *testing.T) {
+	systemBundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(systemBundle)
+
+	snapshot := bs.Snapshot()
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+
+	require.True(t, snapshot.Bundle() == systemBundle)
+	require.Equal(t, uint64(1), snapshot.Sequence())
+	require.Equal(t, systemBundle.MSPManager(), snapshot.MSPManager())
+	require.Equal(t, systemBundle.PolicyManager(), snapshot.PolicyManager())
+	require.Equal(t, systemBundle.ChannelConfig(), snapshot.ChannelConfig())
+
+	_, ok := snapshot.OrdererConfig()
+	require.True(t, ok)
+	_, ok = snapshot.ConsortiumsConfig()
+	require.True(t, ok)
+	_, ok = snapshot.ApplicationConfig()
+	require.True(t, ok)
+
+	_, ok = bs.Snapshot().OrdererConfig()
+	require.False(t, ok)
+	require.Equal(t, uint64(2), bs.Snapshot().Sequence())
+}
@@ -7,11 +7,19 @@ SPDX-License-Identifier: Apache-2.0
 package channelconfig
 
 import (
+	"context"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
 )
 
 // BundleSource stores a reference to the current configuration bundle
@@ -20,31 +28,468 @@ import (
 // so that gross go-routine reads are not vulnerable to out-of-order execution memory
 // type bugs.
 type BundleSource struct {
-	bundle    atomic.Value
-	callbacks []BundleActor
+	generation atomic.Value
+	callbacks  []BundleActor
+	metrics    *Metrics
+	localMSPID string
+
+	// updateLogger, if set, receives a summary of the changes of every Update.
+	updateLogger *flogging.FabricLogger
+
+	// reducedMSP causes stored bundles to be replaced by reduced copies.
+	reducedMSP bool
+
+	// historySize is the number of most recent bundles returned by History.
+	historySize int
+
+	// channelContext, if set, is used by UpdateFromConfig to build bundles.
+	channelContext *channelContext
+
+	// lazyFactory, if set, builds the initial bundle on first access.
+	// lazyMutex serializes its invocations.
+	lazyFactory func() (*Bundle, error)
+	lazyMutex   sync.Mutex
+
+	mutex     sync.Mutex
+	listeners []UpdateListener
+
+	// updateLock is write locked while a new bundle is stored, so that
+	// holders of RLock see no update.
+	updateLock sync.RWMutex
+}
+
+// bundleGeneration pairs a bundle with the sequence number of the Update
+// which stored it, so that both are always loaded together.
+type bundleGeneration struct {
+	bundle   *Bundle
+	sequence uint64
+
+	// previous is the bundle of the generation replaced by this one, or nil
+	// for the initial bundle.
+	previous *Bundle
+
+	// history holds the most recent bundles, newest first, starting with the
+	// bundle of this generation, if WithHistory was set.
+	history []*Bundle
+
+	// superseded is closed once this generation is replaced by an Update.
+	superseded chan struct{}
+
+	// userData holds the values set via SetUserData for this generation.
+	userDataMutex sync.Mutex
+	userData      map[string]interface{}
+}
+
+// channelContext holds what, besides the config, is needed to build a bundle.
+type channelContext struct {
+	channelID      string
+	cryptoProvider bccsp.BCCSP
+	options        []BundleOption
 }
 
 // BundleActor performs an operation based on the given bundle
 type BundleActor func(bundle *Bundle)
 
+// BundleValidator inspects a bundle and returns an error if it must not be
+// published by a BundleSource.
+type BundleValidator func(bundle *Bundle) error
+
+// UpdateListener is notified with the previous and the new bundle whenever
+// the bundle of a BundleSource is replaced.  For the initial bundle, oldBundle
+// is nil.
+type UpdateListener func(oldBundle, newBundle *Bundle)
+
 // NewBundleSource creates a new BundleSource with an initial Bundle value
 // The callbacks will be invoked whenever the Update method is called for the
 // BundleSource.  Note, these callbacks are called immediately before this function
 // returns.
 func NewBundleSource(bundle *Bundle, callbacks ...BundleActor) *BundleSource {
-	bs := &BundleSource{
-		callbacks: callbacks,
+	return NewBundleSourceWithOptions(bundle, WithCallbacks(callbacks...))
+}
+
+// BundleSourceOption configures a BundleSource created via
+// NewBundleSourceWithOptions.
+type BundleSourceOption func(bs *BundleSource)
+
+// WithCallbacks adds callbacks which are invoked whenever the Update method is
+// called for the BundleSource, including for the initial bundle.
+func WithCallbacks(callbacks ...BundleActor) BundleSourceOption {
+	return func(bs *BundleSource) {
+		bs.callbacks = append(bs.callbacks, callbacks...)
+	}
+}
+
+// WithMetricsProvider causes the BundleSource to emit the metrics defined in
+// Metrics through the given provider.  A nil provider disables metrics, which
+// is the default.
+func WithMetricsProvider(provider metrics.Provider) BundleSourceOption {
+	return func(bs *BundleSource) {
+		if provider == nil {
+			bs.metrics = nil
+			return
+		}
+		bs.metrics = NewMetrics(provider)
+	}
+}
+
+// WithLocalMSPID sets the MSP ID of the local org, which is required for
+// OnSelfEviction and checked by SelfCheck.
+func WithLocalMSPID(mspID string) BundleSourceOption {
+	return func(bs *BundleSource) {
+		bs.localMSPID = mspID
+	}
+}
+
+// WithUpdateLogging causes the BundleSource to log the changes of every Update
+// to the given logger, or to the common.channelconfig.bundlesource logger if
+// it is nil.  The changed sections are logged at INFO, and the MSPs, policies,
+// and capabilities which were added, removed, or modified at DEBUG, so the
+// verbosity can be controlled through the logging spec of the logger.
+func WithUpdateLogging(logger *flogging.FabricLogger) BundleSourceOption {
+	return func(bs *BundleSource) {
+		if logger == nil {
+			logger = flogging.MustGetLogger("common.channelconfig.bundlesource")
+		}
+		bs.updateLogger = logger
+	}
+}
+
+// WithHistory causes the BundleSource to retain the given number of most
+// recently stored bundles, including the current one, for History to return
+// them when debugging.  A size of zero, the default, disables the history, in
+// which case no bundles are retained beyond those needed by Rollback.
+func WithHistory(size int) BundleSourceOption {
+	return func(bs *BundleSource) {
+		if size < 0 {
+			size = 0
+		}
+		bs.historySize = size
+	}
+}
+
+// WithChannelContext sets the channel ID, crypto provider, and bundle options
+// which UpdateFromConfig builds bundles with.  Without it, UpdateFromConfig
+// uses those of the current bundle.
+func WithChannelContext(channelID string, cryptoProvider bccsp.BCCSP, opts ...BundleOption) BundleSourceOption {
+	return func(bs *BundleSource) {
+		bs.channelContext = &channelContext{
+			channelID:      channelID,
+			cryptoProvider: cryptoProvider,
+			options:        opts,
+		}
+	}
+}
+
+// NewBundleSourceWithOptions creates a new BundleSource with an initial Bundle
+// value, configured by the given options.
+func NewBundleSourceWithOptions(bundle *Bundle, opts ...BundleSourceOption) *BundleSource {
+	bs := &BundleSource{}
+	for _, opt := range opts {
+		opt(bs)
 	}
 	bs.Update(bundle)
 	return bs
 }
 
+// NewLazyBundleSource creates a new BundleSource whose initial bundle is built
+// by the factory when it is first accessed, rather than immediately.  The
+// factory is invoked until it succeeds, but never concurrently, so that even
+// under concurrent first access a single bundle is built, and the callbacks and
+// listeners registered by then are invoked for the bundle it returns.  If the
+// factory fails, its error is returned by LoadBundle and the other accessors
+// report it like an uninitialized BundleSource, and the factory is invoked
+// again on the next access, so that a transient failure can be recovered
+// from.  If Update is called before the first successful access, the factory
+// is not invoked anymore.
+func NewLazyBundleSource(factory func() (*Bundle, error)) *BundleSource {
+	return &BundleSource{
+		lazyFactory: factory,
+	}
+}
+
+// NewValidatedBundleSource creates a new BundleSource with an initial Bundle
+// value, provided that the bundle passes every validator.  Otherwise, the
+// error of the first failing validator is returned and no BundleSource is
+// created.
+func NewValidatedBundleSource(bundle *Bundle, validators ...BundleValidator) (*BundleSource, error) {
+	if err := validateBundle(bundle, validators); err != nil {
+		return nil, err
+	}
+	return NewBundleSource(bundle), nil
+}
+
+func validateBundle(bundle *Bundle, validators []BundleValidator) error {
+	for _, validator := range validators {
+		if err := validator(bundle); err != nil {
+			return errors.WithMessage(err, "bundle validation failed")
+		}
+	}
+	return nil
+}
+
+// RegisterUpdateListener registers a listener which is invoked on every
+// subsequent call to Update.  Listeners are invoked synchronously, in
+// registration order, after the new bundle has been stored, so they may safely
+// call StableBundle.  A listener must not call Update or RegisterUpdateListener
+// on the same BundleSource, as this would deadlock.
+func (bs *BundleSource) RegisterUpdateListener(listener UpdateListener) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	bs.listeners = append(bs.listeners, listener)
+}
+
 // Update sets a new bundle as the bundle source and calls any registered callbacks
+// and update listeners.  Panics in callbacks or listeners are not recovered: they
+// propagate to the caller of Update after the new bundle has been stored, and any
+// remaining callbacks and listeners are not invoked.
 func (bs *BundleSource) Update(newBundle *Bundle) {
-	bs.bundle.Store(newBundle)
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	bs.update(newBundle)
+}
+
+// UpdateIfChanged behaves like Update, unless the new bundle Equals the current
+// bundle, in which case the current bundle is retained and no callbacks or
+// listeners are invoked.  It returns whether the bundle was replaced.
+func (bs *BundleSource) UpdateIfChanged(newBundle *Bundle) bool {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	if current := bs.current(); current != nil && current.bundle.Equals(newBundle) {
+		return false
+	}
+	bs.update(newBundle)
+	return true
+}
+
+// UpdateMonotonic behaves like Update, unless the current bundle was built from
+// a config block whose number is not lower than the one of the new bundle, as
+// happens when config blocks are replayed, in which case the current bundle is
+// retained and no callbacks or listeners are invoked.  It returns whether the
+// bundle was replaced.  An error is returned if the new bundle does not carry
+// the number of its config block, see NewBundleFromBlock, as it cannot be
+// ordered relative to the current bundle.  A current bundle without a config
+// block number is always replaced.
+func (bs *BundleSource) UpdateMonotonic(newBundle *Bundle) (bool, error) {
+	if newBundle == nil || newBundle.ConfigBlockNumber() == UnknownConfigBlockNumber {
+		return false, errors.New("new bundle does not carry its config block number")
+	}
+
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	if current := bs.current(); current != nil {
+		currentNumber := current.bundle.ConfigBlockNumber()
+		if currentNumber != UnknownConfigBlockNumber && newBundle.ConfigBlockNumber() <= currentNumber {
+			return false, nil
+		}
+	}
+	bs.update(newBundle)
+	return true, nil
+}
+
+// CompareAndUpdate behaves like Update, provided that the sequence of the
+// current bundle, as returned by Sequence or SequencedBundle, equals
+// expectedSeq.  Otherwise, another Update was applied since the caller loaded
+// the current bundle, and the current bundle is retained and no callbacks or
+// listeners are invoked, so that callers can detect the conflict rather than
+// silently overwrite the other update.  It returns whether the bundle was
+// replaced.  An error is returned if the new bundle is nil.
+func (bs *BundleSource) CompareAndUpdate(expectedSeq uint64, newBundle *Bundle) (bool, error) {
+	if newBundle == nil {
+		return false, errors.New("new bundle cannot be nil")
+	}
+
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	var sequence uint64
+	if current := bs.current(); current != nil {
+		sequence = current.sequence
+	}
+	if sequence != expectedSeq {
+		return false, nil
+	}
+	bs.update(newBundle)
+	return true, nil
+}
+
+// UpdateValidated behaves like Update, provided that the new bundle passes
+// every validator.  Otherwise, the error of the first failing validator is
+// returned and the current bundle is retained.
+func (bs *BundleSource) UpdateValidated(newBundle *Bundle, validators ...BundleValidator) error {
+	if err := validateBundle(newBundle, validators); err != nil {
+		return err
+	}
+	bs.Update(newBundle)
+	return nil
+}
+
+// UpdateFromConfig builds a bundle from the config and stores it like Update.
+// The bundle is built for the channel and with the crypto provider and bundle
+// options set via WithChannelContext or, by default, those of the current
+// bundle.  If the bundle cannot be built, the error of NewBundle is returned
+// and the current bundle is retained.
+func (bs *BundleSource) UpdateFromConfig(config *cb.Config) error {
+	bundle, err := bs.buildBundle(config)
+	if err != nil {
+		return err
+	}
+	bs.Update(bundle)
+	return nil
+}
+
+// buildBundle builds a bundle from the config for the channel context of
+// UpdateFromConfig, without storing it.
+func (bs *BundleSource) buildBundle(config *cb.Config) (*Bundle, error) {
+	channel := bs.channelContext
+	if channel == nil {
+		bundle, err := bs.LoadBundle()
+		if err != nil {
+			return nil, err
+		}
+		if bundle.configtxManager == nil {
+			return nil, errors.New("current bundle was not built from a config and no channel context is set")
+		}
+		channel = &channelContext{
+			channelID:      bundle.channelID(),
+			cryptoProvider: bundle.bccsp,
+			options:        bundle.options,
+		}
+	}
+
+	return NewBundle(channel.channelID, config, channel.cryptoProvider, channel.options...)
+}
+
+// DryRun returns the diff between the current bundle and the new bundle,
+// i.e. what an Update with the new bundle would change, without storing the
+// new bundle or invoking any callbacks or listeners.
+func (bs *BundleSource) DryRun(newBundle *Bundle) (*ConfigDiff, error) {
+	if newBundle == nil {
+		return nil, errors.New("new bundle cannot be nil")
+	}
+
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+	return bundle.Diff(newBundle), nil
+}
+
+// History returns the most recently stored bundles, newest first, starting
+// with the current bundle, up to the number set via WithHistory.  This is a
+// debugging aid, e.g. to inspect the config a channel ran with before the
+// latest updates.  Bundles restored by Rollback are recorded again.  If no
+// history was configured or the source is not initialized, nil is returned.
+func (bs *BundleSource) History() []*Bundle {
+	current, err := bs.load()
+	if err != nil || len(current.history) == 0 {
+		return nil
+	}
+	return append([]*Bundle(nil), current.history...)
+}
+
+// Rollback restores the bundle which was replaced by the most recent Update
+// and returns it.  The restore is performed like an Update, so the sequence
+// advances and callbacks and listeners are invoked with the current bundle as
+// the old bundle.  Only the immediately previous bundle is retained, so a
+// second Rollback undoes the first one.  If only the initial bundle has been
+// stored, ErrNoPreviousBundle is returned and the current bundle is retained.
+func (bs *BundleSource) Rollback() (*Bundle, error) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	current := bs.current()
+	if current == nil {
+		return nil, ErrBundleSourceNotInitialized
+	}
+	if current.previous == nil {
+		return nil, ErrNoPreviousBundle
+	}
+	bs.update(current.previous)
+	return current.previous, nil
+}
+
+// update must be called with the mutex held.
+func (bs *BundleSource) update(newBundle *Bundle) {
+	bs.announce(bs.store(newBundle))
+}
+
+// store stores the new bundle as the current generation and returns it.  store
+// must be called with the mutex held, and announce must be called with the
+// returned generation before the mutex is released.
+func (bs *BundleSource) store(newBundle *Bundle) *bundleGeneration {
+	bs.updateLock.Lock()
+	defer bs.updateLock.Unlock()
+
+	if bs.reducedMSP && newBundle != nil {
+		reduced, err := reduceMSPs(newBundle)
+		if err != nil {
+			logger.Warningf("Could not reduce MSPs of bundle for channel %s, storing it as is: %s", newBundle.channelID(), err)
+		} else {
+			newBundle = reduced
+		}
+	}
+
+	next := &bundleGeneration{
+		bundle:     newBundle,
+		sequence:   1,
+		superseded: make(chan struct{}),
+	}
+	current := bs.current()
+	if current != nil {
+		next.previous = current.bundle
+		next.sequence = current.sequence + 1
+	}
+	if bs.historySize > 0 {
+		next.history = append(make([]*Bundle, 0, bs.historySize), newBundle)
+		if current != nil {
+			retained := current.history
+			if len(retained) > bs.historySize-1 {
+				retained = retained[:bs.historySize-1]
+			}
+			next.history = append(next.history, retained...)
+		}
+	}
+
+	bs.generation.Store(next)
+	if current != nil {
+		close(current.superseded)
+	}
+	return next
+}
+
+// announce logs and records metrics for a generation stored via store, and
+// invokes the callbacks and listeners.
+func (bs *BundleSource) announce(next *bundleGeneration) {
+	oldBundle, newBundle := next.previous, next.bundle
+
+	if bs.updateLogger != nil && oldBundle != nil {
+		logUpdate(bs.updateLogger, next.sequence, oldBundle, newBundle)
+	}
+
+	if bs.metrics == nil {
+		bs.notify(oldBundle, newBundle)
+		return
+	}
+
+	channel := newBundle.channelID()
+	bs.metrics.UpdatesApplied.With("channel", channel).Add(1)
+	bs.metrics.Sequence.With("channel", channel).Set(float64(next.sequence))
+	startTime := time.Now()
+	defer func() {
+		bs.metrics.ListenerDuration.With("channel", channel).Observe(time.Since(startTime).Seconds())
+	}()
+	bs.notify(oldBundle, newBundle)
+}
+
+func (bs *BundleSource) notify(oldBundle, newBundle *Bundle) {
 	for _, callback := range bs.callbacks {
 		callback(newBundle)
 	}
+	for _, listener := range bs.listeners {
+		listener(oldBundle, newBundle)
+	}
 }
 
 // StableBundle returns a pointer to a stable Bundle.
@@ -55,8 +500,168 @@ func (bs *BundleSource) Update(newBundle *Bundle) {
 // update might replace the underlying Bundle in between.  Therefore, for operations
 // which require consistency between the Bundle calls, the caller should first retrieve
 // a StableBundle, then operate on it.
+// StableBundle panics with ErrBundleSourceNotInitialized if no bundle has been
+// stored yet, as is the case for a zero value BundleSource, or with the factory
+// error of a lazy BundleSource; use LoadBundle to receive these conditions as
+// an error instead.
 func (bs *BundleSource) StableBundle() *Bundle {
-	return bs.bundle.Load().(*Bundle)
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		panic(err)
+	}
+	return bundle
+}
+
+// LoadBundle returns the current stable bundle, or ErrBundleSourceNotInitialized
+// if no bundle has been stored yet.  For a lazy BundleSource, the first call
+// builds the initial bundle, and the factory error is returned if that fails,
+// in which case the next call tries again.
+func (bs *BundleSource) LoadBundle() (*Bundle, error) {
+	current, err := bs.load()
+	if err != nil {
+		return nil, err
+	}
+	return current.bundle, nil
+}
+
+// WithBundle invokes fn with a single stable bundle and returns its error, so
+// that all the reads fn performs observe the same configuration even if the
+// bundle is replaced concurrently.  If no bundle has been stored yet, fn is not
+// invoked and the error of LoadBundle is returned.
+func (bs *BundleSource) WithBundle(fn func(bundle *Bundle) error) error {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return err
+	}
+	return fn(bundle)
+}
+
+// Sequence returns the number of times the bundle of this BundleSource has
+// been replaced, including the initial bundle.  It starts at 1 for a
+// BundleSource created via NewBundleSource and increases by one with every
+// Update.  It is 0 if no bundle has been stored yet.
+func (bs *BundleSource) Sequence() uint64 {
+	current, err := bs.load()
+	if err != nil {
+		return 0
+	}
+	return current.sequence
+}
+
+// SequencedBundle returns the current stable bundle together with its
+// sequence number, as loaded by a single atomic read.  Like StableBundle, it
+// panics if no bundle has been stored yet.
+func (bs *BundleSource) SequencedBundle() (*Bundle, uint64) {
+	current, err := bs.load()
+	if err != nil {
+		panic(err)
+	}
+	return current.bundle, current.sequence
+}
+
+// BundleToken is an opaque, comparable value identifying the bundle of a
+// BundleSource at the time the token was obtained.  The zero value is not
+// valid for any BundleSource.
+type BundleToken struct {
+	generation *bundleGeneration
+}
+
+// Token returns a token for the current bundle.  Callers deriving values from
+// the bundle may cache them along with the token and recompute them only
+// once TokenValid reports the token as stale.  If no bundle has been stored
+// yet, the zero token is returned.
+func (bs *BundleSource) Token() BundleToken {
+	current, err := bs.load()
+	if err != nil {
+		return BundleToken{}
+	}
+	return BundleToken{generation: current}
+}
+
+// TokenValid returns whether the bundle identified by the token is still the
+// current bundle of this BundleSource.  Any Update, including one storing an
+// equivalent bundle, and any Rollback invalidates previously issued tokens.
+func (bs *BundleSource) TokenValid(token BundleToken) bool {
+	return token.generation != nil && token.generation == bs.current()
+}
+
+// WaitForUpdate blocks until the sequence of this BundleSource is greater than
+// afterSeq and returns the bundle of the first such generation observed.  If
+// the sequence is already greater than afterSeq, it returns immediately.  If
+// the context is done first, the context error is returned.  If no bundle has
+// been stored yet, ErrBundleSourceNotInitialized is returned.
+func (bs *BundleSource) WaitForUpdate(ctx context.Context, afterSeq uint64) (*Bundle, error) {
+	for {
+		current, err := bs.load()
+		if err != nil {
+			return nil, err
+		}
+		if current.sequence > afterSeq {
+			return current.bundle, nil
+		}
+
+		select {
+		case <-current.superseded:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// RLock holds off updates of the bundle until RUnlock is called, so that the
+// bundle returned by StableBundle does not change during a critical section,
+// e.g. while taking a snapshot which embeds the config.  Reads do not require
+// it, as the current bundle is loaded atomically.  Update and all other
+// methods replacing the bundle block while the lock is held, as does any
+// caller of them waiting on the source, hence a holder of the lock must not
+// replace the bundle itself, nor wait for a goroutine which does, and should
+// release it promptly.  Update listeners are invoked after the bundle has been
+// stored and may take the lock.  The initial bundle of a lazy BundleSource is
+// built before the lock is taken.
+func (bs *BundleSource) RLock() {
+	bs.load()
+	bs.updateLock.RLock()
+}
+
+// RUnlock releases a lock taken by RLock.
+func (bs *BundleSource) RUnlock() {
+	bs.updateLock.RUnlock()
+}
+
+// current returns the current generation, or nil if no bundle has been stored.
+func (bs *BundleSource) current() *bundleGeneration {
+	current, _ := bs.generation.Load().(*bundleGeneration)
+	return current
+}
+
+// load returns the current generation, building the initial bundle of a lazy
+// BundleSource if needed.  It must not be called with the mutex held.
+func (bs *BundleSource) load() (*bundleGeneration, error) {
+	if current := bs.current(); current != nil {
+		return current, nil
+	}
+	if bs.lazyFactory == nil {
+		return nil, ErrBundleSourceNotInitialized
+	}
+
+	bs.lazyMutex.Lock()
+	defer bs.lazyMutex.Unlock()
+	if current := bs.current(); current != nil {
+		return current, nil
+	}
+
+	bundle, err := bs.lazyFactory()
+	if err != nil {
+		return nil, err
+	}
+
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	if current := bs.current(); current != nil {
+		return current, nil
+	}
+	bs.update(bundle)
+	return bs.current(), nil
 }
 
 // PolicyManager returns the policy manager constructed for this config
@@ -75,21 +680,35 @@ func (bs *BundleSource) ChannelConfig() Channel {
 }
 
 // OrdererConfig returns the config.Orderer for the channel
-// and whether the Orderer config exists
+// and whether the Orderer config exists.  Like the other pass-through methods
+// returning a bool, it returns false if no bundle has been stored yet, while
+// those without one panic like StableBundle.
 func (bs *BundleSource) OrdererConfig() (Orderer, bool) {
-	return bs.StableBundle().OrdererConfig()
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+	return bundle.OrdererConfig()
 }
 
 // ConsortiumsConfig() returns the config.Consortiums for the channel
 // and whether the consortiums config exists
 func (bs *BundleSource) ConsortiumsConfig() (Consortiums, bool) {
-	return bs.StableBundle().ConsortiumsConfig()
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+	return bundle.ConsortiumsConfig()
 }
 
 // ApplicationConfig returns the Application config for the channel
 // and whether the Application config exists
 func (bs *BundleSource) ApplicationConfig() (Application, bool) {
-	return bs.StableBundle().ApplicationConfig()
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+	return bundle.ApplicationConfig()
 }
 
 // ConfigtxValidator returns the configtx.Validator for the channel
@@ -99,5 +718,9 @@ func (bs *BundleSource) ConfigtxValidator() configtx.Validator {
 
 // ValidateNew passes through to the current bundle
 func (bs *BundleSource) ValidateNew(resources Resources) error {
-	return bs.StableBundle().ValidateNew(resources)
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return err
+	}
+	return bundle.ValidateNew(resources)
 }
@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/golang/protobuf/proto"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// OrgAdmins returns the admin identities explicitly configured for the MSP of
+// the named org in the given group, named as for OrgPolicyManager, as found
+// in a single stable bundle.  The identities are deserialized by the MSP of
+// the org, in the order of the admin certificates of its definition.
+//
+// MSPs which identify their admins by OU, as is the case when node OUs are
+// enabled, need not configure any admin certificates, and the list returned
+// for them may be empty although the org has admins; the same holds for MSPs
+// which are not Fabric MSPs.  To determine whether a given identity holds the
+// admin role of an org, callers should evaluate the Admins policy of the org
+// instead.
+func (bs *BundleSource) OrgAdmins(group, orgName string) ([]msp.Identity, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	fabricConfig, err := orgFabricMSPConfig(bundle, group, orgName)
+	if err != nil || fabricConfig == nil {
+		return nil, err
+	}
+
+	msps, err := bundleMSPs(bundle)
+	if err != nil {
+		return nil, err
+	}
+	orgMSP, ok := msps[fabricConfig.Name]
+	if !ok {
+		return nil, errors.Errorf("org %s references unknown MSP ID %s", orgName, fabricConfig.Name)
+	}
+
+	admins := make([]msp.Identity, 0, len(fabricConfig.Admins))
+	for _, cert := range fabricConfig.Admins {
+		serializedIdentity, err := proto.Marshal(&mspprotos.SerializedIdentity{Mspid: fabricConfig.Name, IdBytes: cert})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal admin identity")
+		}
+		admin, err := orgMSP.DeserializeIdentity(serializedIdentity)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "could not deserialize admin certificate of MSP %s", fabricConfig.Name)
+		}
+		admins = append(admins, admin)
+	}
+	return admins, nil
+}
+
+// OrgOUConfig returns the node OU configuration of the MSP of the named org in
+// the given group, named as for OrgPolicyManager, as found in a single stable
+// bundle, i.e. whether node OUs are enabled and which OU identifiers assign
+// the client, peer, admin, and orderer roles.  If the MSP does not configure
+// node OUs, an empty configuration, with node OUs disabled, is returned.  The
+// second return value is false if the source is not initialized, the org is
+// not found, or its MSP is not a Fabric MSP.
+func (bs *BundleSource) OrgOUConfig(group, orgName string) (*mspprotos.FabricNodeOUs, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+
+	fabricConfig, err := orgFabricMSPConfig(bundle, group, orgName)
+	if err != nil || fabricConfig == nil {
+		return nil, false
+	}
+	if fabricConfig.FabricNodeOus == nil {
+		return &mspprotos.FabricNodeOUs{}, true
+	}
+	return fabricConfig.FabricNodeOus, true
+}
+
+// orgFabricMSPConfig returns the definition of the MSP of the named org in the
+// given group of the bundle, or nil if the MSP is not a Fabric MSP.
+func orgFabricMSPConfig(bundle *Bundle, group, orgName string) (*mspprotos.FabricMSPConfig, error) {
+	path, ok := orgGroupPath(group, orgName)
+	if !ok {
+		return nil, errors.Errorf("org %s not found in group %s", orgName, group)
+	}
+	orgGroup := bundle.ConfigProto().GetChannelGroup()
+	for _, segment := range path {
+		if orgGroup = orgGroup.GetGroups()[segment]; orgGroup == nil {
+			return nil, errors.Errorf("org %s not found in group %s", orgName, group)
+		}
+	}
+
+	value, ok := orgGroup.Values[MSPKey]
+	if !ok {
+		return nil, errors.Errorf("org %s of group %s does not define an MSP", orgName, group)
+	}
+	mspConfig := &mspprotos.MSPConfig{}
+	if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal MSP config")
+	}
+	if mspConfig.Type != int32(msp.FABRIC) {
+		return nil, nil
+	}
+	fabricConfig := &mspprotos.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal fabric MSP config")
+	}
+	return fabricConfig, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceOrgAdmins(t *testing.T) {
+	adminCert, err := ioutil.ReadFile(filepath.Join(configtest.GetDevMspDir(), "admincerts", "admincert.pem"))
+	require.NoError(t, err)
+
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	for _, tc := range []struct {
+		group, orgName string
+	}{
+		{channelconfig.ApplicationGroupKey, "SampleOrg"},
+		{channelconfig.OrdererGroupKey, "SampleOrg"},
+		{channelconfig.ConsortiumsGroupKey, "SampleConsortium/SampleOrg"},
+	} {
+		t.Run(tc.group, func(t *testing.T) {
+			admins, err := bs.OrgAdmins(tc.group, tc.orgName)
+			require.NoError(t, err)
+			require.Len(t, admins, 1)
+			require.Equal(t, "SampleOrg", admins[0].GetMSPIdentifier())
+
+			serialized, err := admins[0].Serialize()
+			require.NoError(t, err)
+			sid := &mspprotos.SerializedIdentity{}
+			require.NoError(t, proto.Unmarshal(serialized, sid))
+			require.Equal(t, adminCert, sid.IdBytes)
+		})
+	}
+
+	t.Run("AdminOU", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundleWithAdminOU(t, genesisconfig.SampleDevModeSoloProfile))
+		admins, err := bs.OrgAdmins(channelconfig.ApplicationGroupKey, "SampleOrg")
+		require.NoError(t, err)
+		require.Empty(t, admins)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := bs.OrgAdmins(channelconfig.ApplicationGroupKey, "Missing")
+		require.EqualError(t, err, "org Missing not found in group Application")
+
+		_, err = bs.OrgAdmins(channelconfig.ConsortiumsGroupKey, "SampleOrg")
+		require.EqualError(t, err, "org SampleOrg not found in group Consortiums")
+
+		_, err = bs.OrgAdmins("Missing", "SampleOrg")
+		require.EqualError(t, err, "org SampleOrg not found in group Missing")
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		_, err := (&channelconfig.BundleSource{}).OrgAdmins(channelconfig.ApplicationGroupKey, "SampleOrg")
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+	})
+}
+
+func TestBundleSourceOrgOUConfig(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	nodeOUs, ok := bs.OrgOUConfig(channelconfig.ApplicationGroupKey, "SampleOrg")
+	require.True(t, ok)
+	require.False(t, nodeOUs.Enable)
+
+	bs.Update(newTestBundleWithAdminOU(t, genesisconfig.SampleDevModeSoloProfile))
+	for _, tc := range []struct {
+		group, orgName string
+	}{
+		{channelconfig.ApplicationGroupKey, "SampleOrg"},
+		{channelconfig.OrdererGroupKey, "SampleOrg"},
+		{channelconfig.ConsortiumsGroupKey, "SampleConsortium/SampleOrg"},
+	} {
+		t.Run(tc.group, func(t *testing.T) {
+			nodeOUs, ok := bs.OrgOUConfig(tc.group, tc.orgName)
+			require.True(t, ok)
+			require.True(t, nodeOUs.Enable)
+			require.Equal(t, "OU_admin", nodeOUs.AdminOuIdentifier.OrganizationalUnitIdentifier)
+			require.Nil(t, nodeOUs.ClientOuIdentifier)
+		})
+	}
+
+	_, ok = bs.OrgOUConfig(channelconfig.ApplicationGroupKey, "Missing")
+	require.False(t, ok)
+
+	_, ok = bs.OrgOUConfig("Missing", "SampleOrg")
+	require.False(t, ok)
+
+	_, ok = (&channelconfig.BundleSource{}).OrgOUConfig(channelconfig.ApplicationGroupKey, "SampleOrg")
+	require.False(t, ok)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"fmt"
+	"sort"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// AnchorPeers returns the anchor peers of all application orgs of a single
+// stable bundle, and whether the Application config exists.  Anchor peers
+// with the same host and port are only returned once.  The peers are ordered
+// by org name, then by their order in the org config.
+func (bs *BundleSource) AnchorPeers() ([]*pb.AnchorPeer, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+
+	ac, ok := bundle.ApplicationConfig()
+	if !ok {
+		return nil, false
+	}
+
+	orgs := ac.Organizations()
+	orgNames := make([]string, 0, len(orgs))
+	for orgName := range orgs {
+		orgNames = append(orgNames, orgName)
+	}
+	sort.Strings(orgNames)
+
+	var anchorPeers []*pb.AnchorPeer
+	for _, orgName := range orgNames {
+		anchorPeers = append(anchorPeers, orgs[orgName].AnchorPeers()...)
+	}
+
+	return dedupAnchorPeers(anchorPeers), true
+}
+
+// AnchorPeersForOrg returns the anchor peers of the application org with the
+// given MSP ID from a single stable bundle, and whether the Application config
+// exists.  If no application org has the given MSP ID, no peers are returned.
+func (bs *BundleSource) AnchorPeersForOrg(mspID string) ([]*pb.AnchorPeer, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+
+	ac, ok := bundle.ApplicationConfig()
+	if !ok {
+		return nil, false
+	}
+
+	var anchorPeers []*pb.AnchorPeer
+	for _, org := range ac.Organizations() {
+		if org.MSPID() == mspID {
+			anchorPeers = append(anchorPeers, org.AnchorPeers()...)
+		}
+	}
+
+	return dedupAnchorPeers(anchorPeers), true
+}
+
+// OnApplicationChange registers a function which is invoked on Update
+// whenever the Application section of the new bundle differs from the one of
+// the previous bundle, so that consumers of application config are not woken
+// by changes to other sections.  Sections are compared by content, hence
+// replacing a bundle with one built from an identical Application group does
+// not invoke the function.  A missing Application config is reported as nil.
+// The same restrictions as for update listeners apply.
+func (bs *BundleSource) OnApplicationChange(fn func(oldApplication, newApplication Application)) {
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if oldBundle == nil {
+			return
+		}
+		oldGroup := subGroup(oldBundle.ConfigProto().GetChannelGroup(), ApplicationGroupKey)
+		newGroup := subGroup(newBundle.ConfigProto().GetChannelGroup(), ApplicationGroupKey)
+		if configGroupsEqual(oldGroup, newGroup) {
+			return
+		}
+		fn(applicationConfig(oldBundle), applicationConfig(newBundle))
+	})
+}
+
+// OnCapabilityActivated registers a function which is invoked exactly once, on
+// the first Update whose new bundle has the named application capability, such
+// as V2_0, while the previous bundle does not, so that one-time migrations can
+// be tied to the activation.  Later updates on which the capability remains
+// active, and reactivations after it has been removed, do not invoke the
+// function again.  The activation state is tracked by the source from the
+// registration on, hence a capability which is already active when the
+// function is registered is only reported once it is removed and added again.
+// The same restrictions as for update listeners apply.
+func (bs *BundleSource) OnCapabilityActivated(capName string, fn func()) {
+	activated := false
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if activated || oldBundle == nil {
+			return
+		}
+		if hasApplicationCapability(oldBundle, capName) || !hasApplicationCapability(newBundle, capName) {
+			return
+		}
+		activated = true
+		fn()
+	})
+}
+
+// hasApplicationCapability returns whether the Application section of the
+// bundle has the named capability.
+func hasApplicationCapability(bundle *Bundle, capName string) bool {
+	if bundle.channelConfig == nil {
+		return false
+	}
+	ac := bundle.channelConfig.ApplicationConfig()
+	if ac == nil {
+		return false
+	}
+	_, ok := ac.protos.Capabilities.GetCapabilities()[capName]
+	return ok
+}
+
+func applicationConfig(bundle *Bundle) Application {
+	ac, ok := bundle.ApplicationConfig()
+	if !ok {
+		return nil
+	}
+	return ac
+}
+
+func dedupAnchorPeers(anchorPeers []*pb.AnchorPeer) []*pb.AnchorPeer {
+	seen := map[string]struct{}{}
+	var result []*pb.AnchorPeer
+	for _, anchorPeer := range anchorPeers {
+		endpoint := fmt.Sprintf("%s:%d", anchorPeer.Host, anchorPeer.Port)
+		if _, ok := seen[endpoint]; ok {
+			continue
+		}
+		seen[endpoint] = struct{}{}
+		result = append(result, anchorPeer)
+	}
+	return result
+}
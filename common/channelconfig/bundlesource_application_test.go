@@ -0,0 +1,151 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceAnchorPeers(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	org := conf.Application.Organizations[0]
+	org.AnchorPeers = []*genesisconfig.AnchorPeer{
+		{Host: "peer0.example.com", Port: 7051},
+		{Host: "peer1.example.com", Port: 7051},
+	}
+	otherOrg := *org
+	otherOrg.Name = "OtherOrg"
+	otherOrg.AnchorPeers = []*genesisconfig.AnchorPeer{
+		{Host: "peer1.example.com", Port: 7051},
+		{Host: "peer2.example.com", Port: 7051},
+	}
+	conf.Application.Organizations = append(conf.Application.Organizations, &otherOrg)
+
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+
+	anchorPeers, ok := bs.AnchorPeers()
+	require.True(t, ok)
+	require.Equal(t, []*pb.AnchorPeer{
+		{Host: "peer1.example.com", Port: 7051},
+		{Host: "peer2.example.com", Port: 7051},
+		{Host: "peer0.example.com", Port: 7051},
+	}, anchorPeers)
+
+	anchorPeers, ok = bs.AnchorPeersForOrg("SampleOrg")
+	require.True(t, ok)
+	require.Len(t, anchorPeers, 3)
+
+	anchorPeers, ok = bs.AnchorPeersForOrg("MissingOrg")
+	require.True(t, ok)
+	require.Empty(t, anchorPeers)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPSoloProfile))
+	anchorPeers, ok = bs.AnchorPeers()
+	require.False(t, ok)
+	require.Nil(t, anchorPeers)
+}
+
+func TestBundleSourceOnApplicationChange(t *testing.T) {
+	loadConf := func() *genesisconfig.Profile {
+		return genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	}
+
+	initial := newTestBundleFromProfile(t, loadConf())
+	bs := channelconfig.NewBundleSource(initial)
+
+	type change struct {
+		oldApplication, newApplication channelconfig.Application
+	}
+	var changes []change
+	bs.OnApplicationChange(func(oldApplication, newApplication channelconfig.Application) {
+		changes = append(changes, change{oldApplication: oldApplication, newApplication: newApplication})
+	})
+
+	bs.Update(newTestBundleFromProfile(t, loadConf()))
+	require.Empty(t, changes)
+
+	conf := loadConf()
+	conf.Orderer.BatchTimeout = 5 * time.Second
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Empty(t, changes)
+
+	previous := bs.StableBundle()
+	conf = loadConf()
+	conf.Application.Capabilities = map[string]bool{"V1_4_2": true}
+	modified := newTestBundleFromProfile(t, conf)
+	bs.Update(modified)
+	require.Len(t, changes, 1)
+	oldApplication, _ := previous.ApplicationConfig()
+	newApplication, _ := modified.ApplicationConfig()
+	require.True(t, changes[0].oldApplication == oldApplication)
+	require.True(t, changes[0].newApplication == newApplication)
+	require.True(t, changes[0].oldApplication.Capabilities().LifecycleV20())
+	require.False(t, changes[0].newApplication.Capabilities().LifecycleV20())
+
+	bs.Update(newTestRaftBundle(t))
+	require.Len(t, changes, 2)
+
+	bs.Update(&channelconfig.Bundle{})
+	require.Len(t, changes, 3)
+	require.NotNil(t, changes[2].oldApplication)
+	require.Nil(t, changes[2].newApplication)
+
+	bs.Update(&channelconfig.Bundle{})
+	require.Len(t, changes, 3)
+}
+
+func TestBundleSourceOnCapabilityActivated(t *testing.T) {
+	bundleWithCapabilities := func(capabilities map[string]bool) *channelconfig.Bundle {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		conf.Application.Capabilities = capabilities
+		return newTestBundleFromProfile(t, conf)
+	}
+
+	bs := channelconfig.NewBundleSource(bundleWithCapabilities(map[string]bool{"V1_4_2": true}))
+
+	activations := 0
+	bs.OnCapabilityActivated("V2_0", func() {
+		activations++
+	})
+	bs.OnCapabilityActivated("V9_9", func() {
+		t.Fatal("capability which never becomes active must not be reported")
+	})
+
+	bs.Update(bundleWithCapabilities(map[string]bool{"V1_4_2": true}))
+	require.Equal(t, 0, activations)
+
+	bs.Update(bundleWithCapabilities(map[string]bool{"V2_0": true}))
+	require.Equal(t, 1, activations)
+
+	bs.Update(bundleWithCapabilities(map[string]bool{"V2_0": true}))
+	require.Equal(t, 1, activations)
+
+	bs.Update(&channelconfig.Bundle{})
+	bs.Update(bundleWithCapabilities(map[string]bool{"V1_4_2": true}))
+	bs.Update(bundleWithCapabilities(map[string]bool{"V2_0": true}))
+	require.Equal(t, 1, activations)
+
+	t.Run("AlreadyActive", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(bundleWithCapabilities(map[string]bool{"V2_0": true}))
+		activations := 0
+		bs.OnCapabilityActivated("V2_0", func() { activations++ })
+
+		bs.Update(bundleWithCapabilities(map[string]bool{"V2_0": true}))
+		require.Equal(t, 0, activations)
+
+		bs.Update(bundleWithCapabilities(map[string]bool{"V1_4_2": true}))
+		bs.Update(bundleWithCapabilities(map[string]bool{"V2_0": true}))
+		require.Equal(t, 1, activations)
+	})
+}
@@ -0,0 +1,204 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import "fmt"
+
+// ChannelCapabilities returns the channel capabilities and whether the
+// Channel config exists.
+func (b *Bundle) ChannelCapabilities() (ChannelCapabilities, bool) {
+	if b.channelConfig == nil {
+		return nil, false
+	}
+	return b.channelConfig.Capabilities(), true
+}
+
+// OrdererCapabilities returns the orderer capabilities and whether the
+// Orderer config exists.
+func (b *Bundle) OrdererCapabilities() (OrdererCapabilities, bool) {
+	oc, ok := b.OrdererConfig()
+	if !ok {
+		return nil, false
+	}
+	return oc.Capabilities(), true
+}
+
+// ApplicationCapabilities returns the application capabilities and whether
+// the Application config exists.
+func (b *Bundle) ApplicationCapabilities() (ApplicationCapabilities, bool) {
+	ac, ok := b.ApplicationConfig()
+	if !ok {
+		return nil, false
+	}
+	return ac.Capabilities(), true
+}
+
+// ChannelCapabilities returns the channel capabilities of the current bundle
+// and whether the Channel config exists.  Callers which inspect capabilities
+// of several sections should retrieve a StableBundle and query it instead, so
+// that all capabilities are drawn from the same config.
+func (bs *BundleSource) ChannelCapabilities() (ChannelCapabilities, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+	return bundle.ChannelCapabilities()
+}
+
+// OrdererCapabilities returns the orderer capabilities of the current bundle
+// and whether the Orderer config exists.
+func (bs *BundleSource) OrdererCapabilities() (OrdererCapabilities, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+	return bundle.OrdererCapabilities()
+}
+
+// ApplicationCapabilities returns the application capabilities of the current
+// bundle and whether the Application config exists.
+func (bs *BundleSource) ApplicationCapabilities() (ApplicationCapabilities, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+	return bundle.ApplicationCapabilities()
+}
+
+// ChannelFeature identifies a feature whose availability on a channel is
+// governed by the capabilities in its config.
+type ChannelFeature int
+
+const (
+	// FeatureOrgSpecificOrdererEndpoints allows orderer orgs to define their
+	// own endpoints, gated by the channel capabilities.
+	FeatureOrgSpecificOrdererEndpoints ChannelFeature = iota
+
+	// FeatureConsensusTypeMigration allows migrating the consensus type of
+	// the ordering service, gated by both the channel and the orderer
+	// capabilities.
+	FeatureConsensusTypeMigration
+
+	// FeatureExpirationCheck causes the orderer to reject messages signed by
+	// expired identities, gated by the orderer capabilities.
+	FeatureExpirationCheck
+
+	// FeatureLifecycleV20 enables the per channel chaincode lifecycle
+	// introduced in v2.0, gated by the application capabilities.
+	FeatureLifecycleV20
+
+	// FeatureACLs allows ACLs to be defined in the Application group, gated
+	// by the application capabilities.
+	FeatureACLs
+
+	// FeaturePrivateChannelData enables private data collections, gated by
+	// the application capabilities.
+	FeaturePrivateChannelData
+
+	// FeatureKeyLevelEndorsement enables key level endorsement policies,
+	// gated by the application capabilities.
+	FeatureKeyLevelEndorsement
+
+	// FeatureStorePvtDataOfInvalidTx causes peers to store the private data
+	// of invalid transactions, gated by the application capabilities.
+	FeatureStorePvtDataOfInvalidTx
+)
+
+var channelFeatureNames = map[ChannelFeature]string{
+	FeatureOrgSpecificOrdererEndpoints: "OrgSpecificOrdererEndpoints",
+	FeatureConsensusTypeMigration:      "ConsensusTypeMigration",
+	FeatureExpirationCheck:             "ExpirationCheck",
+	FeatureLifecycleV20:                "LifecycleV20",
+	FeatureACLs:                        "ACLs",
+	FeaturePrivateChannelData:          "PrivateChannelData",
+	FeatureKeyLevelEndorsement:         "KeyLevelEndorsement",
+	FeatureStorePvtDataOfInvalidTx:     "StorePvtDataOfInvalidTx",
+}
+
+func (f ChannelFeature) String() string {
+	if name, ok := channelFeatureNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("ChannelFeature(%d)", int(f))
+}
+
+// Supports returns whether the capabilities of the bundle enable the given
+// feature.  A feature gated by the capabilities of a section which the config
+// does not contain is not supported, nor is an unknown feature.
+func (b *Bundle) Supports(feature ChannelFeature) bool {
+	switch feature {
+	case FeatureOrgSpecificOrdererEndpoints:
+		cc, ok := b.ChannelCapabilities()
+		return ok && cc.OrgSpecificOrdererEndpoints()
+	case FeatureConsensusTypeMigration:
+		cc, ok := b.ChannelCapabilities()
+		if !ok || !cc.ConsensusTypeMigration() {
+			return false
+		}
+		oc, ok := b.OrdererCapabilities()
+		return ok && oc.ConsensusTypeMigration()
+	case FeatureExpirationCheck:
+		oc, ok := b.OrdererCapabilities()
+		return ok && oc.ExpirationCheck()
+	}
+
+	ac, ok := b.ApplicationCapabilities()
+	if !ok {
+		return false
+	}
+	switch feature {
+	case FeatureLifecycleV20:
+		return ac.LifecycleV20()
+	case FeatureACLs:
+		return ac.ACLs()
+	case FeaturePrivateChannelData:
+		return ac.PrivateChannelData()
+	case FeatureKeyLevelEndorsement:
+		return ac.KeyLevelEndorsement()
+	case FeatureStorePvtDataOfInvalidTx:
+		return ac.StorePvtDataOfInvalidTx()
+	default:
+		return false
+	}
+}
+
+// Supports returns whether the capabilities of the current bundle enable the
+// given feature, see Bundle.Supports.  It returns false if no bundle has been
+// stored yet.
+func (bs *BundleSource) Supports(feature ChannelFeature) bool {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return false
+	}
+	return bundle.Supports(feature)
+}
+
+// EffectiveChannelVersion returns the major and minor version of the highest
+// version capability, such as V1_4_3 or V2_0, enabled in the Channel group of
+// the current bundle, so that clients can select their behavior by comparing
+// version numbers instead of evaluating capabilities.  Channel capabilities
+// are cumulative, so when several are enabled the highest one determines the
+// behavior of the channel and the lower ones are ignored; patch components,
+// as in V1_4_3, are dropped.  If no version capability is enabled, the channel
+// behaves as in v1.0 and 1, 0 is returned.  If no bundle has been stored yet,
+// 0, 0 is returned.
+func (bs *BundleSource) EffectiveChannelVersion() (major, minor int) {
+	bundle, err := bs.LoadBundle()
+	if err != nil || bundle.channelConfig == nil {
+		return 0, 0
+	}
+
+	_, level := capabilityLevel(bundle.channelConfig.protos.Capabilities)
+	switch len(level) {
+	case 0:
+		return 1, 0
+	case 1:
+		return level[0], 0
+	default:
+		return level[0], level[1]
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceCapabilities(t *testing.T) {
+	t.Run("SystemChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+		channelCaps, ok := bs.ChannelCapabilities()
+		require.True(t, ok)
+		require.True(t, channelCaps.OrgSpecificOrdererEndpoints())
+
+		ordererCaps, ok := bs.OrdererCapabilities()
+		require.True(t, ok)
+		require.True(t, ordererCaps.ExpirationCheck())
+
+		appCaps, ok := bs.ApplicationCapabilities()
+		require.True(t, ok)
+		require.True(t, appCaps.LifecycleV20())
+	})
+
+	t.Run("ApplicationChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+
+		_, ok := bs.ChannelCapabilities()
+		require.True(t, ok)
+
+		ordererCaps, ok := bs.OrdererCapabilities()
+		require.False(t, ok)
+		require.Nil(t, ordererCaps)
+
+		_, ok = bs.ApplicationCapabilities()
+		require.True(t, ok)
+	})
+}
+
+func TestBundleSourceSupports(t *testing.T) {
+	allFeatures := []channelconfig.ChannelFeature{
+		channelconfig.FeatureOrgSpecificOrdererEndpoints,
+		channelconfig.FeatureConsensusTypeMigration,
+		channelconfig.FeatureExpirationCheck,
+		channelconfig.FeatureLifecycleV20,
+		channelconfig.FeatureACLs,
+		channelconfig.FeaturePrivateChannelData,
+		channelconfig.FeatureKeyLevelEndorsement,
+		channelconfig.FeatureStorePvtDataOfInvalidTx,
+	}
+
+	t.Run("SystemChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+		for _, feature := range allFeatures {
+			require.True(t, bs.Supports(feature), feature.String())
+		}
+		require.False(t, bs.Supports(channelconfig.ChannelFeature(-1)))
+	})
+
+	t.Run("ApplicationChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+		require.True(t, bs.Supports(channelconfig.FeatureLifecycleV20))
+		require.False(t, bs.Supports(channelconfig.FeatureExpirationCheck))
+		require.False(t, bs.Supports(channelconfig.FeatureConsensusTypeMigration))
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		require.False(t, (&channelconfig.BundleSource{}).Supports(channelconfig.FeatureACLs))
+	})
+
+	require.Equal(t, "LifecycleV20", channelconfig.FeatureLifecycleV20.String())
+	require.Equal(t, "ChannelFeature(42)", channelconfig.ChannelFeature(42).String())
+}
+
+func TestBundleSourceEffectiveChannelVersion(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		capabilities map[string]bool
+		major, minor int
+	}{
+		{name: "V2_0", capabilities: map[string]bool{"V2_0": true}, major: 2, minor: 0},
+		{name: "Patch", capabilities: map[string]bool{"V1_4_3": true}, major: 1, minor: 4},
+		{name: "Highest", capabilities: map[string]bool{"V1_3": true, "V1_4_3": true, "V1_4_2": true}, major: 1, minor: 4},
+		{name: "Disabled", capabilities: map[string]bool{"V2_0": false, "V1_3": true}, major: 1, minor: 3},
+		{name: "None", major: 1, minor: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// org specific orderer endpoints require V1_4_2
+			conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+			conf.Orderer.Addresses = []string{"127.0.0.1:7050"}
+			conf.Orderer.Organizations[0].OrdererEndpoints = nil
+			conf.Capabilities = tc.capabilities
+			bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+
+			major, minor := bs.EffectiveChannelVersion()
+			require.Equal(t, tc.major, major)
+			require.Equal(t, tc.minor, minor)
+		})
+	}
+
+	major, minor := (&channelconfig.BundleSource{}).EffectiveChannelVersion()
+	require.Zero(t, major)
+	require.Zero(t, minor)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/internal/configtxlator/update"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// ValidateConfigUpdate checks, against a single stable bundle, whether the
+// config update would be accepted by the config transaction validation,
+// without applying it.  The elements the update modifies are determined from
+// its read and write sets, and the signatures of the envelope are evaluated
+// against the mod policies of these elements in the current config.  If a mod
+// policy is not satisfied, the returned error names the modified element,
+// e.g. [Group]  /Channel/Application.  Stale read sets and version mismatches
+// are reported as well.
+func (bs *BundleSource) ValidateConfigUpdate(env *cb.ConfigUpdateEnvelope) error {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return err
+	}
+	if env == nil {
+		return errors.New("config update envelope cannot be nil")
+	}
+
+	validator := bundle.ConfigtxValidator()
+	if validator == nil {
+		return errors.New("bundle has no config validator")
+	}
+
+	data, err := protoutil.Marshal(env)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config update envelope")
+	}
+	chdr := protoutil.MakeChannelHeader(cb.HeaderType_CONFIG_UPDATE, 0, validator.ChannelID(), 0)
+	payload, err := protoutil.Marshal(&cb.Payload{
+		Header: protoutil.MakePayloadHeader(chdr, &cb.SignatureHeader{}),
+		Data:   data,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config update payload")
+	}
+
+	if _, err := validator.ProposeConfigUpdate(&cb.Envelope{Payload: payload}); err != nil {
+		return errors.WithMessage(err, "config update rejected")
+	}
+	return nil
+}
+
+// GenerateUpdate computes the minimal config update transforming the config
+// of a single stable bundle into the target config, as configtxlator does.
+// The read set of the update references the elements of the current config
+// the update depends on, and the write set carries the modified elements with
+// incremented versions, so that the update, once signed, is accepted by
+// ValidateConfigUpdate and the config transaction validation.  The update is
+// addressed to the channel of the bundle.  An error is returned if the bundle
+// was not built from a config or if the target config does not differ from it.
+func (bs *BundleSource) GenerateUpdate(target *cb.Config) (*cb.ConfigUpdate, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errors.New("target config cannot be nil")
+	}
+	if bundle.ConfigProto() == nil {
+		return nil, errors.New("bundle was not built from a config")
+	}
+
+	configUpdate, err := update.Compute(bundle.ConfigProto(), target)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to compute config update")
+	}
+	configUpdate.ChannelId = bundle.channelID()
+	return configUpdate, nil
+}
+
+// ApplyPartial builds a bundle from a copy of the config of the current bundle
+// as modified by the mutator, e.g. to change a single value without
+// assembling the entire config.  The bundle is built like UpdateFromConfig
+// does, and is therefore fully validated, but it is not stored.  The config
+// of the current bundle is not affected by the mutator.
+func (bs *BundleSource) ApplyPartial(mutator func(config *cb.Config)) (*Bundle, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+	if bundle.ConfigProto() == nil {
+		return nil, errors.New("bundle was not built from a config")
+	}
+
+	config := proto.Clone(bundle.ConfigProto()).(*cb.Config)
+	mutator(config)
+	return bs.buildBundle(config)
+}
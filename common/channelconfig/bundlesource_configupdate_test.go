@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/internal/configtxlator/update"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceValidateConfigUpdate(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(bundle)
+
+	updated := proto.Clone(bundle.ConfigProto()).(*cb.Config)
+	capabilities := updated.ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Values[channelconfig.CapabilitiesKey]
+	capabilities.Value = protoutil.MarshalOrPanic(&cb.Capabilities{
+		Capabilities: map[string]*cb.Capability{"V1_4_2": {}},
+	})
+	configUpdate, err := update.Compute(bundle.ConfigProto(), updated)
+	require.NoError(t, err)
+	configUpdate.ChannelId = "testchannel"
+
+	newEnvelope := func(signed bool) *cb.ConfigUpdateEnvelope {
+		env := &cb.ConfigUpdateEnvelope{ConfigUpdate: protoutil.MarshalOrPanic(configUpdate)}
+		if signed {
+			signer := newTestSigner(t)
+			sigHeader, err := protoutil.NewSignatureHeader(signer)
+			require.NoError(t, err)
+			configSig := &cb.ConfigSignature{SignatureHeader: protoutil.MarshalOrPanic(sigHeader)}
+			configSig.Signature, err = signer.Sign(util.ConcatenateBytes(configSig.SignatureHeader, env.ConfigUpdate))
+			require.NoError(t, err)
+			env.Signatures = []*cb.ConfigSignature{configSig}
+		}
+		return env
+	}
+
+	t.Run("Authorized", func(t *testing.T) {
+		require.NoError(t, bs.ValidateConfigUpdate(newEnvelope(true)))
+		require.True(t, bs.StableBundle() == bundle)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		err := bs.ValidateConfigUpdate(newEnvelope(false))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "config update rejected: error authorizing update: error validating DeltaSet: policy for [Value]  /Channel/Application/Capabilities not satisfied")
+	})
+
+	t.Run("WrongChannel", func(t *testing.T) {
+		configUpdate.ChannelId = "otherchannel"
+		defer func() { configUpdate.ChannelId = "testchannel" }()
+		err := bs.ValidateConfigUpdate(newEnvelope(true))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ConfigUpdate for channel 'otherchannel' but envelope for channel 'testchannel'")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		require.EqualError(t, bs.ValidateConfigUpdate(nil), "config update envelope cannot be nil")
+		require.EqualError(t, channelconfig.NewBundleSource(&channelconfig.Bundle{}).ValidateConfigUpdate(newEnvelope(true)), "bundle has no config validator")
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, (&channelconfig.BundleSource{}).ValidateConfigUpdate(newEnvelope(true)))
+	})
+}
+
+func TestBundleSourceGenerateUpdate(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(bundle)
+
+	target := proto.Clone(bundle.ConfigProto()).(*cb.Config)
+	capabilities := target.ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Values[channelconfig.CapabilitiesKey]
+	capabilities.Value = protoutil.MarshalOrPanic(&cb.Capabilities{
+		Capabilities: map[string]*cb.Capability{"V1_4_2": {}},
+	})
+
+	configUpdate, err := bs.GenerateUpdate(target)
+	require.NoError(t, err)
+	require.Equal(t, "testchannel", configUpdate.ChannelId)
+	expected, err := update.Compute(bundle.ConfigProto(), target)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(expected.WriteSet, configUpdate.WriteSet))
+	require.True(t, proto.Equal(expected.ReadSet, configUpdate.ReadSet))
+
+	env := &cb.ConfigUpdateEnvelope{ConfigUpdate: protoutil.MarshalOrPanic(configUpdate)}
+	signer := newTestSigner(t)
+	sigHeader, err := protoutil.NewSignatureHeader(signer)
+	require.NoError(t, err)
+	configSig := &cb.ConfigSignature{SignatureHeader: protoutil.MarshalOrPanic(sigHeader)}
+	configSig.Signature, err = signer.Sign(util.ConcatenateBytes(configSig.SignatureHeader, env.ConfigUpdate))
+	require.NoError(t, err)
+	env.Signatures = []*cb.ConfigSignature{configSig}
+	require.NoError(t, bs.ValidateConfigUpdate(env))
+
+	_, err = bs.GenerateUpdate(bundle.ConfigProto())
+	require.EqualError(t, err, "failed to compute config update: no differences detected between original and updated config")
+
+	_, err = bs.GenerateUpdate(nil)
+	require.EqualError(t, err, "target config cannot be nil")
+
+	_, err = channelconfig.NewBundleSource(&channelconfig.Bundle{}).GenerateUpdate(target)
+	require.EqualError(t, err, "bundle was not built from a config")
+
+	_, err = (&channelconfig.BundleSource{}).GenerateUpdate(target)
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceApplyPartial(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(bundle)
+
+	applied, err := bs.ApplyPartial(func(config *cb.Config) {
+		batchTimeout := config.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.BatchTimeoutKey]
+		batchTimeout.Value = protoutil.MarshalOrPanic(&ab.BatchTimeout{Timeout: "5s"})
+	})
+	require.NoError(t, err)
+	oc, ok := applied.OrdererConfig()
+	require.True(t, ok)
+	require.Equal(t, 5*time.Second, oc.BatchTimeout())
+	require.Equal(t, &channelconfig.ConfigDiff{Orderer: true}, bundle.Diff(applied))
+	require.True(t, bs.StableBundle() == bundle)
+	oc, _ = bundle.OrdererConfig()
+	require.Equal(t, 2*time.Second, oc.BatchTimeout())
+
+	danglingModPolicy := func(config *cb.Config) {
+		config.ChannelGroup.Groups[channelconfig.ApplicationGroupKey].ModPolicy = "Missing"
+	}
+	_, err = bs.ApplyPartial(danglingModPolicy)
+	require.NoError(t, err)
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	strict, err := channelconfig.NewBundle("testchannel", bundle.ConfigProto(), cryptoProvider, channelconfig.WithPolicyReferenceValidation())
+	require.NoError(t, err)
+	_, err = channelconfig.NewBundleSource(strict).ApplyPartial(danglingModPolicy)
+	var dangling *channelconfig.DanglingPolicyError
+	require.True(t, errors.As(err, &dangling))
+
+	_, err = (&channelconfig.BundleSource{}).ApplyPartial(func(*cb.Config) {})
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// ConsenterCertChange describes how the TLS certificates of a consenter,
+// identified by its host:port endpoint, differ between two bundles.  The old
+// certificates are nil for a consenter the new bundle adds, and the new
+// certificates are nil for a consenter it removes.
+type ConsenterCertChange struct {
+	Endpoint         string
+	OldClientTLSCert []byte
+	NewClientTLSCert []byte
+	OldServerTLSCert []byte
+	NewServerTLSCert []byte
+}
+
+// OnConsenterCertChange registers a function which is invoked on Update when
+// the TLS certificates of the etcdraft consenters differ between the previous
+// and the new bundle, e.g. so that the cluster communication layer can pin the
+// rotated certificates.  The changes are sorted by endpoint.  Consenters are
+// matched by endpoint, and a bundle without an etcdraft Orderer config is
+// treated as having no consenters.  The same restrictions as for update
+// listeners apply.
+func (bs *BundleSource) OnConsenterCertChange(fn func(changes []ConsenterCertChange)) {
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if oldBundle == nil {
+			return
+		}
+
+		oldConsenters, err := raftConsenters(oldBundle)
+		if err != nil {
+			logger.Warningf("Could not inspect the consenters of previous bundle: %s", err)
+			return
+		}
+		newConsenters, err := raftConsenters(newBundle)
+		if err != nil {
+			logger.Warningf("Could not inspect the consenters of new bundle: %s", err)
+			return
+		}
+
+		if changes := consenterCertChanges(oldConsenters, newConsenters); len(changes) > 0 {
+			fn(changes)
+		}
+	})
+}
+
+// raftConsenters returns the consenters of the etcdraft consensus metadata of
+// the bundle keyed by their host:port endpoint.  No consenters are returned if
+// the bundle has no Orderer config or its consensus type is not etcdraft.
+func raftConsenters(bundle *Bundle) (map[string]*etcdraft.Consenter, error) {
+	oc, ok := bundle.OrdererConfig()
+	if !ok || oc.ConsensusType() != "etcdraft" {
+		return nil, nil
+	}
+
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(oc.ConsensusMetadata(), metadata); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+	}
+
+	consenters := make(map[string]*etcdraft.Consenter, len(metadata.Consenters))
+	for _, consenter := range metadata.Consenters {
+		consenters[fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)] = consenter
+	}
+	return consenters, nil
+}
+
+func consenterCertChanges(oldConsenters, newConsenters map[string]*etcdraft.Consenter) []ConsenterCertChange {
+	endpoints := map[string]struct{}{}
+	for endpoint := range oldConsenters {
+		endpoints[endpoint] = struct{}{}
+	}
+	for endpoint := range newConsenters {
+		endpoints[endpoint] = struct{}{}
+	}
+	sortedEndpoints := make([]string, 0, len(endpoints))
+	for endpoint := range endpoints {
+		sortedEndpoints = append(sortedEndpoints, endpoint)
+	}
+	sort.Strings(sortedEndpoints)
+
+	var changes []ConsenterCertChange
+	for _, endpoint := range sortedEndpoints {
+		oldConsenter, newConsenter := oldConsenters[endpoint], newConsenters[endpoint]
+		change := ConsenterCertChange{
+			Endpoint:         endpoint,
+			OldClientTLSCert: oldConsenter.GetClientTlsCert(),
+			NewClientTLSCert: newConsenter.GetClientTlsCert(),
+			OldServerTLSCert: oldConsenter.GetServerTlsCert(),
+			NewServerTLSCert: newConsenter.GetServerTlsCert(),
+		}
+		if oldConsenter != nil && newConsenter != nil &&
+			bytes.Equal(change.OldClientTLSCert, change.NewClientTLSCert) &&
+			bytes.Equal(change.OldServerTLSCert, change.NewServerTLSCert) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,65 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceOnConsenterCertChange(t *testing.T) {
+	readCert := func(name string) []byte {
+		cert, err := ioutil.ReadFile("testdata/" + name)
+		require.NoError(t, err)
+		return cert
+	}
+
+	bs := channelconfig.NewBundleSource(newTestRaftBundle(t))
+	var changes [][]channelconfig.ConsenterCertChange
+	bs.OnConsenterCertChange(func(c []channelconfig.ConsenterCertChange) {
+		changes = append(changes, c)
+	})
+
+	bs.Update(newTestRaftBundle(t))
+	require.Empty(t, changes)
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeEtcdRaftProfile, configtest.GetDevConfigDir())
+	consenters := conf.Orderer.EtcdRaft.Consenters
+	consenters[0].ClientTlsCert = []byte("testdata/tls-client-3.pem")
+	consenters[0].ServerTlsCert = []byte("testdata/tls-server-1.pem")
+	consenters[1].ClientTlsCert = []byte("testdata/tls-client-2.pem")
+	consenters[1].ServerTlsCert = []byte("testdata/tls-server-2.pem")
+	conf.Orderer.EtcdRaft.Consenters = consenters[:2]
+	bs.Update(newTestBundleFromProfile(t, conf))
+
+	require.Len(t, changes, 1)
+	require.Len(t, changes[0], 2)
+	require.Equal(t, channelconfig.ConsenterCertChange{
+		Endpoint:         "raft0.example.com:7050",
+		OldClientTLSCert: readCert("tls-client-1.pem"),
+		NewClientTLSCert: readCert("tls-client-3.pem"),
+		OldServerTLSCert: readCert("tls-server-1.pem"),
+		NewServerTLSCert: readCert("tls-server-1.pem"),
+	}, changes[0][0])
+	require.Equal(t, channelconfig.ConsenterCertChange{
+		Endpoint:         "raft2.example.com:7050",
+		OldClientTLSCert: readCert("tls-client-3.pem"),
+		OldServerTLSCert: readCert("tls-server-3.pem"),
+	}, changes[0][1])
+
+	changes = nil
+	bs.Update(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.Len(t, changes, 1)
+	require.Len(t, changes[0], 2)
+	require.Nil(t, changes[0][0].NewClientTLSCert)
+	require.Nil(t, changes[0][1].NewServerTLSCert)
+}
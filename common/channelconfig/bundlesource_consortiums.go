@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/protoutil"
+)
+
+// ConsortiumOrgs returns the orgs of the named consortium from a single stable
+// bundle, keyed by org name, and whether the consortium exists.  If the
+// channel config has no Consortiums config at all, ErrNoConsortiumsConfig is
+// returned instead, so that callers can tell a misdirected lookup from an
+// unknown consortium.  The returned map may be modified by the caller.
+func (bs *BundleSource) ConsortiumOrgs(consortiumName string) (map[string]Org, bool, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false, err
+	}
+
+	cc, ok := bundle.ConsortiumsConfig()
+	if !ok {
+		return nil, false, ErrNoConsortiumsConfig
+	}
+
+	consortium, ok := cc.Consortiums()[consortiumName]
+	if !ok {
+		return nil, false, nil
+	}
+
+	return consortium.Organizations(), true, nil
+}
+
+// IsSystemChannel returns whether the bundle is the config of an orderer
+// system channel.  The defining characteristic of a system channel is that
+// its config contains a Consortiums group, from which the consortiums allowed
+// to create application channels are read; application channels never
+// contain one.
+func (b *Bundle) IsSystemChannel() bool {
+	if b.channelConfig == nil {
+		return false
+	}
+	_, ok := b.ConsortiumsConfig()
+	return ok
+}
+
+// IsSystemChannel returns whether the current bundle is the config of an
+// orderer system channel, see Bundle.IsSystemChannel.  It returns false if no
+// bundle has been stored yet.
+func (bs *BundleSource) IsSystemChannel() bool {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return false
+	}
+	return bundle.IsSystemChannel()
+}
+
+// ChannelCreationPolicy resolves the ChannelCreationPolicy of the named
+// consortium from a single stable bundle, and returns it and whether it
+// exists.  As for the channels the orderer creates, implicit meta policies
+// are resolved against the orgs of the consortium, of which the new channel
+// admits a subset.  It returns false if the channel config has no Consortiums
+// config, the consortium does not exist or defines no creation policy, or the
+// policy cannot be compiled.
+func (bs *BundleSource) ChannelCreationPolicy(consortiumName string) (policies.Policy, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+
+	cc, ok := bundle.ConsortiumsConfig()
+	if !ok {
+		return nil, false
+	}
+	consortium, ok := cc.Consortiums()[consortiumName]
+	if !ok || consortium.ChannelCreationPolicy() == nil {
+		return nil, false
+	}
+	consortiumGroup := subGroup(subGroup(bundle.ConfigProto().GetChannelGroup(), ConsortiumsGroupKey), consortiumName)
+	if consortiumGroup == nil {
+		return nil, false
+	}
+
+	path := RootGroupKey + policies.PathSeparator + ConsortiumsGroupKey + policies.PathSeparator + consortiumName
+	policyManager, err := policies.NewManagerImpl(path, newPolicyProviderMap(bundle.MSPManager()), &cb.ConfigGroup{
+		Groups: consortiumGroup.Groups,
+		Policies: map[string]*cb.ConfigPolicy{
+			ChannelCreationPolicyKey: {Policy: consortium.ChannelCreationPolicy()},
+		},
+	})
+	if err != nil {
+		logger.Warningf("Could not compile the channel creation policy of consortium %s: %s", consortiumName, err)
+		return nil, false
+	}
+	return policyManager.GetPolicy(ChannelCreationPolicyKey)
+}
+
+// CanCreateChannel evaluates the ChannelCreationPolicy of the named consortium,
+// see ChannelCreationPolicy, against the given signature set.  It returns a
+// *PolicyNotFoundError if the policy cannot be resolved, and a
+// *PolicyDeniedError if the signature set does not satisfy it.
+func (bs *BundleSource) CanCreateChannel(consortiumName string, signatureSet []*protoutil.SignedData) error {
+	policyName := channelCreationPolicyPath(consortiumName)
+	policy, ok := bs.ChannelCreationPolicy(consortiumName)
+	if !ok {
+		return &PolicyNotFoundError{PolicyName: policyName}
+	}
+	if err := policy.EvaluateSignedData(signatureSet); err != nil {
+		return &PolicyDeniedError{PolicyName: policyName, Err: err}
+	}
+	return nil
+}
+
+func channelCreationPolicyPath(consortiumName string) string {
+	return policies.PathSeparator + RootGroupKey + policies.PathSeparator + ConsortiumsGroupKey + policies.PathSeparator + consortiumName + policies.PathSeparator + ChannelCreationPolicyKey
+}
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceConsortiumOrgs(t *testing.T) {
+	t.Run("SystemChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+		orgs, ok, err := bs.ConsortiumOrgs("SampleConsortium")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, orgs, 1)
+		require.Equal(t, "SampleOrg", orgs["SampleOrg"].MSPID())
+
+		// the returned map is a copy
+		delete(orgs, "SampleOrg")
+		orgs, _, _ = bs.ConsortiumOrgs("SampleConsortium")
+		require.Len(t, orgs, 1)
+
+		orgs, ok, err = bs.ConsortiumOrgs("UnknownConsortium")
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Nil(t, orgs)
+	})
+
+	t.Run("ApplicationChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+		orgs, ok, err := bs.ConsortiumOrgs("SampleConsortium")
+		require.Equal(t, channelconfig.ErrNoConsortiumsConfig, err)
+		require.False(t, ok)
+		require.Nil(t, orgs)
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		_, _, err := (&channelconfig.BundleSource{}).ConsortiumOrgs("SampleConsortium")
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+	})
+}
+
+func TestBundleSourceIsSystemChannel(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.True(t, bs.IsSystemChannel())
+	require.True(t, bs.StableBundle().IsSystemChannel())
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	require.False(t, bs.IsSystemChannel())
+	require.False(t, bs.StableBundle().IsSystemChannel())
+
+	require.False(t, (&channelconfig.Bundle{}).IsSystemChannel())
+	require.False(t, (&channelconfig.BundleSource{}).IsSystemChannel())
+}
+
+func TestBundleSourceChannelCreationPolicy(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	signedData := newTestSignedData(t, []byte("data"))
+	badSignature := &protoutil.SignedData{Data: []byte("other data"), Identity: signedData.Identity, Signature: signedData.Signature}
+
+	policy, ok := bs.ChannelCreationPolicy("SampleConsortium")
+	require.True(t, ok)
+	require.NoError(t, policy.EvaluateSignedData([]*protoutil.SignedData{signedData}))
+
+	require.NoError(t, bs.CanCreateChannel("SampleConsortium", []*protoutil.SignedData{signedData}))
+
+	err := bs.CanCreateChannel("SampleConsortium", []*protoutil.SignedData{badSignature})
+	require.IsType(t, &channelconfig.PolicyDeniedError{}, err)
+	require.Contains(t, err.Error(), "policy /Channel/Consortiums/SampleConsortium/ChannelCreationPolicy not satisfied")
+
+	_, ok = bs.ChannelCreationPolicy("UnknownConsortium")
+	require.False(t, ok)
+	require.EqualError(t, bs.CanCreateChannel("UnknownConsortium", nil), "policy /Channel/Consortiums/UnknownConsortium/ChannelCreationPolicy not found")
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	_, ok = bs.ChannelCreationPolicy("SampleConsortium")
+	require.False(t, ok)
+	require.IsType(t, &channelconfig.PolicyNotFoundError{}, bs.CanCreateChannel("SampleConsortium", []*protoutil.SignedData{signedData}))
+
+	_, ok = (&channelconfig.BundleSource{}).ChannelCreationPolicy("SampleConsortium")
+	require.False(t, ok)
+}
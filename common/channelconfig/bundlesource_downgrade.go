@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"strconv"
+	"strings"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/pkg/errors"
+)
+
+// UpdateNoDowngrade behaves like Update, unless the new bundle lowers the
+// capability level of the Channel, Orderer, or Application section compared
+// to the current bundle, in which case an error naming the section and the
+// capability which would be downgraded is returned and the current bundle is
+// retained.  The capability level of a section is the highest version
+// capability it enables, e.g. V2_0, so dropping V1_4_2 while V2_0 remains
+// enabled is not a downgrade.  A nil bundle enables no capabilities.
+func (bs *BundleSource) UpdateNoDowngrade(newBundle *Bundle) error {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	if current := bs.current(); current != nil {
+		if err := checkCapabilityDowngrade(current.bundle, newBundle); err != nil {
+			return err
+		}
+	}
+	bs.update(newBundle)
+	return nil
+}
+
+func checkCapabilityDowngrade(oldBundle, newBundle *Bundle) error {
+	oldSections, newSections := capabilitySections(oldBundle), capabilitySections(newBundle)
+	for i, section := range oldSections {
+		oldName, oldLevel := capabilityLevel(section.capabilities)
+		if oldLevel == nil {
+			continue
+		}
+		newName, newLevel := capabilityLevel(newSections[i].capabilities)
+		if compareCapabilityLevels(newLevel, oldLevel) >= 0 {
+			continue
+		}
+		if newLevel == nil {
+			newName = "no capability"
+		}
+		return errors.Errorf("%s capability %s would be downgraded to %s", section.name, oldName, newName)
+	}
+	return nil
+}
+
+type capabilitySection struct {
+	name         string
+	capabilities *cb.Capabilities
+}
+
+// capabilitySections returns the capabilities of the Channel, Orderer, and
+// Application sections of the bundle, in this order.  The capabilities of a
+// section which the bundle does not contain, or of any section of a nil
+// bundle, are nil.
+func capabilitySections(bundle *Bundle) []capabilitySection {
+	sections := []capabilitySection{
+		{name: ChannelGroupKey},
+		{name: OrdererGroupKey},
+		{name: ApplicationGroupKey},
+	}
+
+	if bundle == nil || bundle.channelConfig == nil {
+		return sections
+	}
+	cc := bundle.channelConfig
+	sections[0].capabilities = cc.protos.Capabilities
+	if oc := cc.OrdererConfig(); oc != nil {
+		sections[1].capabilities = oc.protos.Capabilities
+	}
+	if ac := cc.ApplicationConfig(); ac != nil {
+		sections[2].capabilities = ac.protos.Capabilities
+	}
+	return sections
+}
+
+// capabilityLevel returns the name and the parsed version of the highest
+// version capability, such as V1_4_2, among the given capabilities.  Names
+// which are not of this form are ignored.  If there is no version capability,
+// the returned version is nil.
+func capabilityLevel(capabilities *cb.Capabilities) (string, []int) {
+	var name string
+	var level []int
+	for capability := range capabilities.GetCapabilities() {
+		version, ok := parseCapabilityVersion(capability)
+		if !ok {
+			continue
+		}
+		if cmp := compareCapabilityLevels(version, level); cmp > 0 || (cmp == 0 && capability < name) {
+			name, level = capability, version
+		}
+	}
+	return name, level
+}
+
+func parseCapabilityVersion(capability string) ([]int, bool) {
+	if !strings.HasPrefix(capability, "V") {
+		return nil, false
+	}
+	var version []int
+	for _, component := range strings.Split(capability[1:], "_") {
+		n, err := strconv.Atoi(component)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		version = append(version, n)
+	}
+	return version, true
+}
+
+// compareCapabilityLevels returns -1, 0, or 1 if version a is lower than,
+// equal to, or higher than version b.  Missing trailing components are
+// treated as zero, and a nil version is lower than any other.
+func compareCapabilityLevels(a, b []int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBundleWithApplicationCapabilities(t *testing.T, capabilities map[string]bool) *channelconfig.Bundle {
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	conf.Application.Capabilities = capabilities
+	return newTestBundleFromProfile(t, conf)
+}
+
+func TestBundleSourceUpdateNoDowngrade(t *testing.T) {
+	v20 := newTestBundleWithApplicationCapabilities(t, map[string]bool{"V2_0": true})
+	bs := channelconfig.NewBundleSource(v20)
+
+	t.Run("Downgrade", func(t *testing.T) {
+		err := bs.UpdateNoDowngrade(newTestBundleWithApplicationCapabilities(t, map[string]bool{"V1_4_2": true}))
+		require.EqualError(t, err, "Application capability V2_0 would be downgraded to V1_4_2")
+		require.True(t, bs.StableBundle() == v20)
+		require.Equal(t, uint64(1), bs.Sequence())
+	})
+
+	t.Run("Removal", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		conf.Application.Capabilities = nil
+		conf.Application.ACLs = nil
+		err := bs.UpdateNoDowngrade(newTestBundleFromProfile(t, conf))
+		require.EqualError(t, err, "Application capability V2_0 would be downgraded to no capability")
+		require.True(t, bs.StableBundle() == v20)
+	})
+
+	t.Run("NilBundle", func(t *testing.T) {
+		err := bs.UpdateNoDowngrade(nil)
+		require.EqualError(t, err, "Channel capability V2_0 would be downgraded to no capability")
+		require.True(t, bs.StableBundle() == v20)
+
+		empty := channelconfig.NewBundleSource(nil)
+		require.NoError(t, empty.UpdateNoDowngrade(nil))
+		require.NoError(t, empty.UpdateNoDowngrade(v20))
+		require.True(t, empty.StableBundle() == v20)
+	})
+
+	t.Run("SameLevel", func(t *testing.T) {
+		next := newTestBundleWithApplicationCapabilities(t, map[string]bool{"V1_4_2": true, "V2_0": true})
+		require.NoError(t, bs.UpdateNoDowngrade(next))
+		require.True(t, bs.StableBundle() == next)
+
+		next = newTestBundleWithApplicationCapabilities(t, map[string]bool{"V2_0": true})
+		require.NoError(t, bs.UpdateNoDowngrade(next))
+		require.True(t, bs.StableBundle() == next)
+	})
+
+	t.Run("Upgrade", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundleWithApplicationCapabilities(t, map[string]bool{"V1_4_2": true}))
+		next := newTestBundleWithApplicationCapabilities(t, map[string]bool{"V2_0": true})
+		require.NoError(t, bs.UpdateNoDowngrade(next))
+		require.True(t, bs.StableBundle() == next)
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		bs := &channelconfig.BundleSource{}
+		require.NoError(t, bs.UpdateNoDowngrade(v20))
+		require.True(t, bs.StableBundle() == v20)
+	})
+}
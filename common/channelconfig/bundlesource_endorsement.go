@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/policydsl"
+	"github.com/pkg/errors"
+)
+
+// endorsementPolicyName is the name of the Application policy which the V2
+// lifecycle references as the default endorsement policy.
+const endorsementPolicyName = "Endorsement"
+
+// DefaultEndorsementPolicy resolves the /Channel/Application/Endorsement
+// policy of a single stable bundle, which the V2 lifecycle applies to
+// chaincodes not defining their own endorsement policy, into the equivalent
+// signature policy.  Implicit meta policies, such as MAJORITY Endorsement, are
+// replaced by a threshold over the sub policies of the org groups, which are
+// ordered by org name, and the principals of all orgs are merged into the
+// identities of the returned envelope.  An error is returned if the channel
+// has no Application group or no Endorsement policy, which indicates a channel
+// configured before the V2 lifecycle, or if a sub policy of an org is missing.
+func (bs *BundleSource) DefaultEndorsementPolicy() (*cb.SignaturePolicyEnvelope, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	group := subGroup(bundle.ConfigProto().GetChannelGroup(), ApplicationGroupKey)
+	if group == nil {
+		return nil, errors.Errorf("channel config has no %s group", ApplicationGroupKey)
+	}
+	path := policies.PathSeparator + RootGroupKey + policies.PathSeparator + ApplicationGroupKey
+	if _, ok := group.Policies[endorsementPolicyName]; !ok {
+		return nil, errors.Errorf("channel config has no %s%s%s policy, the channel does not support the V2 lifecycle", path, policies.PathSeparator, endorsementPolicyName)
+	}
+
+	resolver := &signaturePolicyResolver{}
+	rule, err := resolver.resolve(path, group, endorsementPolicyName)
+	if err != nil {
+		return nil, err
+	}
+	return &cb.SignaturePolicyEnvelope{
+		Rule:       rule,
+		Identities: resolver.identities,
+	}, nil
+}
+
+// signaturePolicyResolver merges the identities of the signature policies it
+// resolves, so that their rules can be combined in a single envelope.
+type signaturePolicyResolver struct {
+	identities []*mspprotos.MSPPrincipal
+}
+
+// resolve returns the rule of the named policy of the group at the given
+// path, with the principal indices referring to the merged identities.
+func (r *signaturePolicyResolver) resolve(path string, group *cb.ConfigGroup, policyName string) (*cb.SignaturePolicy, error) {
+	policyPath := path + policies.PathSeparator + policyName
+	configPolicy, ok := group.Policies[policyName]
+	if !ok || configPolicy.Policy == nil {
+		return nil, errors.Errorf("policy %s is missing", policyPath)
+	}
+
+	switch cb.Policy_PolicyType(configPolicy.Policy.Type) {
+	case cb.Policy_SIGNATURE:
+		envelope := &cb.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, envelope); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal signature policy %s", policyPath)
+		}
+		indices := make([]int32, len(envelope.Identities))
+		for i, identity := range envelope.Identities {
+			indices[i] = r.identityIndex(identity)
+		}
+		return remapSignedBy(policyPath, envelope.Rule, indices)
+	case cb.Policy_IMPLICIT_META:
+		implicitMeta := &cb.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, implicitMeta); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal implicit meta policy %s", policyPath)
+		}
+		var rules []*cb.SignaturePolicy
+		for _, name := range sortedGroupNames(group.Groups) {
+			rule, err := r.resolve(path+policies.PathSeparator+name, group.Groups[name], implicitMeta.SubPolicy)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+		return policydsl.NOutOf(implicitMetaThreshold(implicitMeta.Rule, len(rules)), rules), nil
+	default:
+		return nil, errors.Errorf("policy %s is of unsupported type %d", policyPath, configPolicy.Policy.Type)
+	}
+}
+
+// identityIndex returns the index of the principal in the merged identities,
+// adding it if it is not yet present.
+func (r *signaturePolicyResolver) identityIndex(identity *mspprotos.MSPPrincipal) int32 {
+	for i, existing := range r.identities {
+		if proto.Equal(existing, identity) {
+			return int32(i)
+		}
+	}
+	r.identities = append(r.identities, identity)
+	return int32(len(r.identities) - 1)
+}
+
+// remapSignedBy returns a copy of the rule with each principal index i
+// replaced by indices[i].
+func remapSignedBy(policyPath string, rule *cb.SignaturePolicy, indices []int32) (*cb.SignaturePolicy, error) {
+	switch t := rule.GetType().(type) {
+	case *cb.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || int(t.SignedBy) >= len(indices) {
+			return nil, errors.Errorf("signature policy %s references identity %d, but only %d are defined", policyPath, t.SignedBy, len(indices))
+		}
+		return policydsl.SignedBy(indices[t.SignedBy]), nil
+	case *cb.SignaturePolicy_NOutOf_:
+		rules := make([]*cb.SignaturePolicy, len(t.NOutOf.Rules))
+		for i, subRule := range t.NOutOf.Rules {
+			var err error
+			if rules[i], err = remapSignedBy(policyPath, subRule, indices); err != nil {
+				return nil, err
+			}
+		}
+		return policydsl.NOutOf(t.NOutOf.N, rules), nil
+	default:
+		return nil, errors.Errorf("signature policy %s has no rule", policyPath)
+	}
+}
+
+// implicitMetaThreshold returns how many of the given number of sub policies
+// must be satisfied under the rule, as computed by the implicit meta policy.
+func implicitMetaThreshold(rule cb.ImplicitMetaPolicy_Rule, subPolicies int) int32 {
+	if subPolicies == 0 {
+		return 0
+	}
+	switch rule {
+	case cb.ImplicitMetaPolicy_ANY:
+		return 1
+	case cb.ImplicitMetaPolicy_ALL:
+		return int32(subPolicies)
+	case cb.ImplicitMetaPolicy_MAJORITY:
+		return int32(subPolicies/2 + 1)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/policydsl"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceDefaultEndorsementPolicy(t *testing.T) {
+	// loadConf returns a profile with the application orgs Org2, Org3, and
+	// SampleOrg, where the Endorsement policy of Org3 also references the
+	// members of SampleOrg.
+	loadConf := func() *genesisconfig.Profile {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		sampleOrg := conf.Application.Organizations[0]
+		for _, rule := range []struct{ name, rule string }{
+			{name: "Org2", rule: "OR('Org2MSP.member')"},
+			{name: "Org3", rule: "AND('Org3MSP.peer', 'SampleOrg.member')"},
+		} {
+			org := *sampleOrg
+			org.Name, org.ID = rule.name, rule.name+"MSP"
+			org.Policies = map[string]*genesisconfig.Policy{}
+			for name, policy := range sampleOrg.Policies {
+				org.Policies[name] = policy
+			}
+			org.Policies["Endorsement"] = &genesisconfig.Policy{Type: "Signature", Rule: rule.rule}
+			conf.Application.Organizations = append(conf.Application.Organizations, &org)
+		}
+		return conf
+	}
+
+	principal := func(mspID string, role mspprotos.MSPRole_MSPRoleType) *mspprotos.MSPPrincipal {
+		return &mspprotos.MSPPrincipal{
+			PrincipalClassification: mspprotos.MSPPrincipal_ROLE,
+			Principal:               protoutil.MarshalOrPanic(&mspprotos.MSPRole{MspIdentifier: mspID, Role: role}),
+		}
+	}
+
+	t.Run("MajorityOfOrgs", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, loadConf()))
+		envelope, err := bs.DefaultEndorsementPolicy()
+		require.NoError(t, err)
+
+		expected := &cb.SignaturePolicyEnvelope{
+			Rule: policydsl.NOutOf(2, []*cb.SignaturePolicy{
+				policydsl.NOutOf(1, []*cb.SignaturePolicy{policydsl.SignedBy(0)}),
+				policydsl.NOutOf(2, []*cb.SignaturePolicy{policydsl.SignedBy(1), policydsl.SignedBy(2)}),
+				policydsl.NOutOf(1, []*cb.SignaturePolicy{policydsl.SignedBy(2)}),
+			}),
+			Identities: []*mspprotos.MSPPrincipal{
+				principal("Org2MSP", mspprotos.MSPRole_MEMBER),
+				principal("Org3MSP", mspprotos.MSPRole_PEER),
+				principal("SampleOrg", mspprotos.MSPRole_MEMBER),
+			},
+		}
+		require.True(t, proto.Equal(expected, envelope), "expected %v, got %v", expected, envelope)
+	})
+
+	t.Run("AnyOrg", func(t *testing.T) {
+		conf := loadConf()
+		conf.Application.Policies["Endorsement"] = &genesisconfig.Policy{Type: "ImplicitMeta", Rule: "ANY Endorsement"}
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+		envelope, err := bs.DefaultEndorsementPolicy()
+		require.NoError(t, err)
+		require.Equal(t, int32(1), envelope.Rule.GetNOutOf().N)
+		require.Len(t, envelope.Rule.GetNOutOf().Rules, 3)
+	})
+
+	t.Run("SignaturePolicy", func(t *testing.T) {
+		conf := loadConf()
+		conf.Application.Policies["Endorsement"] = &genesisconfig.Policy{Type: "Signature", Rule: "OR('Org2MSP.member')"}
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+		envelope, err := bs.DefaultEndorsementPolicy()
+		require.NoError(t, err)
+		require.True(t, proto.Equal(&cb.SignaturePolicyEnvelope{
+			Rule:       policydsl.NOutOf(1, []*cb.SignaturePolicy{policydsl.SignedBy(0)}),
+			Identities: []*mspprotos.MSPPrincipal{principal("Org2MSP", mspprotos.MSPRole_MEMBER)},
+		}, envelope))
+	})
+
+	t.Run("MissingOrgPolicy", func(t *testing.T) {
+		conf := loadConf()
+		delete(conf.Application.Organizations[1].Policies, "Endorsement")
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+		_, err := bs.DefaultEndorsementPolicy()
+		require.EqualError(t, err, "policy /Channel/Application/Org2/Endorsement is missing")
+	})
+
+	t.Run("PreV2Channel", func(t *testing.T) {
+		conf := loadConf()
+		delete(conf.Application.Policies, "Endorsement")
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+		_, err := bs.DefaultEndorsementPolicy()
+		require.EqualError(t, err, "channel config has no /Channel/Application/Endorsement policy, the channel does not support the V2 lifecycle")
+	})
+
+	t.Run("NoApplication", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+		_, err := bs.DefaultEndorsementPolicy()
+		require.EqualError(t, err, "channel config has no Application group")
+	})
+}
@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/golang/protobuf/proto"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// OnSelfEviction registers a function which is invoked on Update with the new
+// bundle whenever it evicts the local org set via WithLocalMSPID, i.e. when the
+// previous bundle defines the local MSP and the new bundle does not, when the
+// new definition of the local MSP grants the admin role neither by certificate
+// nor by OU, or when none of the admin certificates of the local MSP in the
+// previous bundle is an admin according to the MSP of the new bundle.  Admin
+// certificate rotations therefore do not evict the org as long as a previous
+// admin keeps the admin role, e.g. through the admin OU.  If no local MSP ID
+// was set, the function is never invoked.  The same restrictions as for update
+// listeners apply.
+func (bs *BundleSource) OnSelfEviction(fn func(newBundle *Bundle)) {
+	mspID := bs.localMSPID
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if mspID == "" || oldBundle == nil {
+			return
+		}
+
+		oldPresent, oldConfig, err := localFabricMSPConfig(oldBundle, mspID)
+		if err != nil {
+			logger.Warningf("Could not inspect MSP %s of previous bundle: %s", mspID, err)
+			return
+		}
+		newPresent, newConfig, err := localFabricMSPConfig(newBundle, mspID)
+		if err != nil {
+			logger.Warningf("Could not inspect MSP %s of new bundle: %s", mspID, err)
+			return
+		}
+
+		if !oldPresent {
+			return
+		}
+		if !newPresent {
+			logger.Warningf("Config update removes local MSP %s", mspID)
+			fn(newBundle)
+			return
+		}
+		if oldConfig == nil || !grantsAdmin(oldConfig) {
+			return
+		}
+		if newConfig != nil && !grantsAdmin(newConfig) {
+			logger.Warningf("Config update revokes the admins of local MSP %s", mspID)
+			fn(newBundle)
+			return
+		}
+		if len(oldConfig.Admins) == 0 {
+			return
+		}
+
+		stillAdmin, err := anyAdmin(newBundle, mspID, oldConfig.Admins)
+		if err != nil {
+			logger.Warningf("Could not check the admins of MSP %s against the new bundle: %s", mspID, err)
+			return
+		}
+		if !stillAdmin {
+			logger.Warningf("Config update revokes the admins of local MSP %s", mspID)
+			fn(newBundle)
+		}
+	})
+}
+
+// localFabricMSPConfig returns whether the MSP manager of the bundle was built
+// from a definition of the MSP with the given ID, and that definition, or nil
+// if the MSP is not a Fabric MSP.
+func localFabricMSPConfig(bundle *Bundle, mspID string) (present bool, fabricConfig *mspprotos.FabricMSPConfig, err error) {
+	mspConfigs, err := collectMSPConfigs(bundle.mspConfigProto().GetChannelGroup())
+	if err != nil {
+		return false, nil, err
+	}
+
+	mspConfig, ok := mspConfigs[mspID]
+	if !ok {
+		return false, nil, nil
+	}
+	if mspConfig.Type != int32(msp.FABRIC) {
+		return true, nil, nil
+	}
+
+	fabricConfig = &mspprotos.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+		return true, nil, errors.Wrap(err, "failed to unmarshal fabric MSP config")
+	}
+	return true, fabricConfig, nil
+}
+
+// grantsAdmin returns whether the MSP definition names any admin certificate
+// or, with node OUs enabled, an admin OU.
+func grantsAdmin(fabricConfig *mspprotos.FabricMSPConfig) bool {
+	if len(fabricConfig.Admins) != 0 {
+		return true
+	}
+	nodeOUs := fabricConfig.FabricNodeOus
+	return nodeOUs.GetEnable() && nodeOUs.GetAdminOuIdentifier() != nil
+}
+
+// anyAdmin returns whether any of the given certificates satisfies the admin
+// role of the MSP with the given ID in the bundle.  Certificates which the MSP
+// cannot deserialize are not admins.
+func anyAdmin(bundle *Bundle, mspID string, certs [][]byte) (bool, error) {
+	msps, err := bundleMSPs(bundle)
+	if err != nil {
+		return false, err
+	}
+	localMSP, ok := msps[mspID]
+	if !ok {
+		return false, errors.Errorf("MSP %s not found in MSP manager", mspID)
+	}
+
+	role, err := proto.Marshal(&mspprotos.MSPRole{MspIdentifier: mspID, Role: mspprotos.MSPRole_ADMIN})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to marshal admin role")
+	}
+	principal := &mspprotos.MSPPrincipal{
+		PrincipalClassification: mspprotos.MSPPrincipal_ROLE,
+		Principal:               role,
+	}
+
+	for _, cert := range certs {
+		serializedIdentity, err := proto.Marshal(&mspprotos.SerializedIdentity{Mspid: mspID, IdBytes: cert})
+		if err != nil {
+			return false, errors.Wrap(err, "failed to marshal admin identity")
+		}
+		identity, err := localMSP.DeserializeIdentity(serializedIdentity)
+		if err != nil {
+			continue
+		}
+		if identity.SatisfiesPrincipal(principal) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBundleWithAdminOU returns a bundle for the given profile whose MSP
+// definitions identify admins by OU rather than by certificate.
+func newTestBundleWithAdminOU(t *testing.T, profile string) *channelconfig.Bundle {
+	return newTestBundleWithFabricMSPConfig(t, profile, func(fabricConfig *mspprotos.FabricMSPConfig) {
+		fabricConfig.Admins = nil
+		fabricConfig.FabricNodeOus = &mspprotos.FabricNodeOUs{
+			Enable:            true,
+			AdminOuIdentifier: &mspprotos.FabricOUIdentifier{OrganizationalUnitIdentifier: "OU_admin"},
+		}
+	})
+}
+
+// newTestBundleWithFabricMSPConfig returns a bundle for the given profile
+// whose MSP definitions are modified by the given function.
+func newTestBundleWithFabricMSPConfig(t *testing.T, profile string, modify func(*mspprotos.FabricMSPConfig)) *channelconfig.Bundle {
+	config := proto.Clone(newTestBundle(t, profile).ConfigProto()).(*cb.Config)
+
+	var walk func(group *cb.ConfigGroup)
+	walk = func(group *cb.ConfigGroup) {
+		if value, ok := group.Values[channelconfig.MSPKey]; ok {
+			mspConfig := &mspprotos.MSPConfig{}
+			require.NoError(t, proto.Unmarshal(value.Value, mspConfig))
+			fabricConfig := &mspprotos.FabricMSPConfig{}
+			require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricConfig))
+			modify(fabricConfig)
+			mspConfig.Config = protoutil.MarshalOrPanic(fabricConfig)
+			value.Value = protoutil.MarshalOrPanic(mspConfig)
+		}
+		for _, child := range group.Groups {
+			walk(child)
+		}
+	}
+	walk(config.ChannelGroup)
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	bundle, err := channelconfig.NewBundle("testchannel", config, cryptoProvider)
+	require.NoError(t, err)
+	return bundle
+}
+
+func TestBundleSourceOnSelfEviction(t *testing.T) {
+	profile := genesisconfig.SampleSingleMSPChannelProfile
+
+	conf := genesisconfig.Load(profile, configtest.GetDevConfigDir())
+	for _, org := range conf.Application.Organizations {
+		org.ID = "OtherOrg"
+	}
+	withoutSampleOrg := newTestBundleFromProfile(t, conf)
+
+	bs := channelconfig.NewBundleSourceWithOptions(newTestBundle(t, profile), channelconfig.WithLocalMSPID("SampleOrg"))
+	var evictions []*channelconfig.Bundle
+	bs.OnSelfEviction(func(newBundle *channelconfig.Bundle) {
+		evictions = append(evictions, newBundle)
+	})
+
+	bs.Update(newTestBundle(t, profile))
+	require.Empty(t, evictions)
+
+	bs.Update(withoutSampleOrg)
+	require.Len(t, evictions, 1)
+	require.True(t, evictions[0] == withoutSampleOrg)
+
+	// an org that was not present cannot be evicted again
+	bs.Update(withoutSampleOrg)
+	require.Len(t, evictions, 1)
+
+	bs.Update(newTestBundle(t, profile))
+	require.Len(t, evictions, 1)
+
+	adminOU := newTestBundleWithAdminOU(t, profile)
+	bs.Update(adminOU)
+	require.Len(t, evictions, 2)
+	require.True(t, evictions[1] == adminOU)
+
+	bs.Update(newTestBundleWithAdminOU(t, profile))
+	require.Len(t, evictions, 2)
+
+	t.Run("AdminCertRotation", func(t *testing.T) {
+		// The org trusts the CAs of both admins and classifies admins by OU,
+		// so the admin certificate replaced by the update remains an admin.
+		mspConfig, err := msp.GetVerifyingMspConfig(filepath.Join("..", "..", "msp", "testdata", "nodeouadmin"), "SampleOrg", "bccsp")
+		require.NoError(t, err)
+		nodeOUConfig := &mspprotos.FabricMSPConfig{}
+		require.NoError(t, proto.Unmarshal(mspConfig.Config, nodeOUConfig))
+		for _, ou := range []*mspprotos.FabricOUIdentifier{
+			nodeOUConfig.FabricNodeOus.ClientOuIdentifier,
+			nodeOUConfig.FabricNodeOus.PeerOuIdentifier,
+			nodeOUConfig.FabricNodeOus.AdminOuIdentifier,
+			nodeOUConfig.FabricNodeOus.OrdererOuIdentifier,
+		} {
+			ou.Certificate = nil
+		}
+		otherCA, err := ioutil.ReadFile(filepath.Join("..", "..", "msp", "testdata", "nodeouadminclient", "cacerts", "ca.example.com-cert.pem"))
+		require.NoError(t, err)
+		nodeOUConfig.RootCerts = append(nodeOUConfig.RootCerts, otherCA)
+
+		adminCert, err := ioutil.ReadFile(filepath.Join("..", "..", "msp", "testdata", "nodeouadmin", "adm", "testadmincert.pem"))
+		require.NoError(t, err)
+		rotatedCert, err := ioutil.ReadFile(filepath.Join("..", "..", "msp", "testdata", "nodeouadminclient", "admincerts", "admin.pem"))
+		require.NoError(t, err)
+
+		withAdmin := func(admin []byte) *channelconfig.Bundle {
+			return newTestBundleWithFabricMSPConfig(t, profile, func(fabricConfig *mspprotos.FabricMSPConfig) {
+				fabricConfig.Reset()
+				proto.Merge(fabricConfig, nodeOUConfig)
+				fabricConfig.Admins = [][]byte{admin}
+			})
+		}
+
+		bs := channelconfig.NewBundleSourceWithOptions(withAdmin(adminCert), channelconfig.WithLocalMSPID("SampleOrg"))
+		bs.OnSelfEviction(func(*channelconfig.Bundle) { t.Fatal("unexpected eviction") })
+		bs.Update(withAdmin(rotatedCert))
+	})
+
+	t.Run("NoLocalMSPID", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, profile))
+		bs.OnSelfEviction(func(*channelconfig.Bundle) { t.Fatal("unexpected eviction") })
+		bs.Update(withoutSampleOrg)
+	})
+}
@@ -0,0 +1,32 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+// HashingAlgorithm returns the name of the hashing algorithm declared by the
+// Channel config of the current bundle, e.g. SHA256, and whether the Channel
+// config exists.  Code which hashes block data should retrieve a
+// StableBundle and query its ChannelConfig instead if it needs the width of
+// the block data hashing structure as well, so that both are drawn from the
+// same config.
+func (bs *BundleSource) HashingAlgorithm() (string, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil || bundle.channelConfig == nil {
+		return "", false
+	}
+	return bundle.channelConfig.protos.HashingAlgorithm.GetName(), true
+}
+
+// BlockDataHashingWidth returns the width of the block data hashing structure
+// declared by the Channel config of the current bundle, and whether the
+// Channel config exists.
+func (bs *BundleSource) BlockDataHashingWidth() (uint32, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil || bundle.channelConfig == nil {
+		return 0, false
+	}
+	return bundle.channelConfig.BlockDataHashingStructureWidth(), true
+}
@@ -0,0 +1,36 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceHashing(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+
+	algorithm, ok := bs.HashingAlgorithm()
+	require.True(t, ok)
+	require.Equal(t, "SHA256", algorithm)
+
+	width, ok := bs.BlockDataHashingWidth()
+	require.True(t, ok)
+	require.Equal(t, uint32(math.MaxUint32), width)
+
+	var uninitialized channelconfig.BundleSource
+	algorithm, ok = uninitialized.HashingAlgorithm()
+	require.False(t, ok)
+	require.Empty(t, algorithm)
+	width, ok = uninitialized.BlockDataHashingWidth()
+	require.False(t, ok)
+	require.Zero(t, width)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// SequenceHeader is the response header in which the handler returned by
+// ConfigHandler reports the sequence of the rendered bundle.
+const SequenceHeader = "X-Bundle-Sequence"
+
+// ConfigHandler returns a read-only http.Handler which serves the JSON
+// representation of the current bundle, as rendered by Bundle.MarshalJSON, in
+// response to GET requests.  The sequence of the rendered bundle, as returned
+// by Sequence, is set in the SequenceHeader so that clients can detect stale
+// responses.  As the rendering contains no certificates or key material, the
+// handler may be registered with the operations server.
+func (bs *BundleSource) ConfigHandler() http.Handler {
+	return &configHandler{bundleSource: bs}
+}
+
+type configHandler struct {
+	bundleSource *BundleSource
+}
+
+type configErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *configHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Cache-Control", "no-store")
+
+	if req.Method != http.MethodGet {
+		h.sendError(resp, http.StatusBadRequest, fmt.Errorf("invalid request method: %s", req.Method))
+		return
+	}
+
+	current, err := h.bundleSource.load()
+	if err != nil {
+		h.sendError(resp, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	js, err := current.bundle.MarshalJSON()
+	if err != nil {
+		logger.Errorw("failed to render channel config", "error", err)
+		h.sendError(resp, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set(SequenceHeader, strconv.FormatUint(current.sequence, 10))
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(js)
+}
+
+func (h *configHandler) sendError(resp http.ResponseWriter, code int, err error) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(code)
+	if err := json.NewEncoder(resp).Encode(&configErrorResponse{Error: err.Error()}); err != nil {
+		logger.Errorw("failed to encode error response", "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceConfigHandler(t *testing.T) {
+	t.Run("Get", func(t *testing.T) {
+		bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+		bs := channelconfig.NewBundleSource(bundle)
+		bs.Update(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+		resp := httptest.NewRecorder()
+		bs.ConfigHandler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/config", nil))
+
+		require.Equal(t, http.StatusOK, resp.Code)
+		require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+		require.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+		require.Equal(t, "2", resp.Header().Get(channelconfig.SequenceHeader))
+		require.NotContains(t, resp.Body.String(), "CERTIFICATE")
+
+		expected, err := json.Marshal(bundle)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), resp.Body.String())
+	})
+
+	t.Run("InvalidMethod", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+		resp := httptest.NewRecorder()
+		bs.ConfigHandler().ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/config", nil))
+
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+		require.JSONEq(t, `{"error":"invalid request method: PUT"}`, resp.Body.String())
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		bs := &channelconfig.BundleSource{}
+
+		resp := httptest.NewRecorder()
+		bs.ConfigHandler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/config", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		require.Empty(t, resp.Header().Get(channelconfig.SequenceHeader))
+		require.JSONEq(t, `{"error":"bundle source has not been initialized with a bundle"}`, resp.Body.String())
+	})
+}
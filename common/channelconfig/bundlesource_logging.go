@@ -0,0 +1,118 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"go.uber.org/zap/zapcore"
+)
+
+// logUpdate logs the sections which differ between the old and the new bundle
+// at INFO, or at DEBUG if there are none.  The MSPs, policies, and
+// capabilities which were added, removed, or modified are logged at DEBUG,
+// unless either bundle is nil.
+func logUpdate(logger *flogging.FabricLogger, sequence uint64, oldBundle, newBundle *Bundle) {
+	diff := oldBundle.Diff(newBundle)
+	channel := newBundle.channelID()
+	if diff.Empty() {
+		logger.Debugw("Bundle updated", "channel", channel, "sequence", sequence, "changes", diff.String())
+		return
+	}
+	logger.Infow("Bundle updated", "channel", channel, "sequence", sequence, "changes", diff.String())
+
+	// a nil bundle has no MSPs, policies, or capabilities to describe
+	if oldBundle == nil || newBundle == nil || !logger.IsEnabledFor(zapcore.DebugLevel) {
+		return
+	}
+	details := append([]interface{}{"channel", channel, "sequence", sequence}, describeChanges(oldBundle, newBundle)...)
+	logger.Debugw("Bundle update details", details...)
+}
+
+// describeChanges returns key value pairs listing the MSPs, policies, and
+// capabilities which differ between the two bundles, neither of which may be
+// nil.  Empty lists are omitted.
+func describeChanges(oldBundle, newBundle *Bundle) []interface{} {
+	var kvPairs []interface{}
+	add := func(key string, values []string) {
+		if len(values) > 0 {
+			kvPairs = append(kvPairs, key, values)
+		}
+	}
+
+	oldMSPs, oldErr := collectMSPConfigs(oldBundle.ConfigProto().GetChannelGroup())
+	newMSPs, newErr := collectMSPConfigs(newBundle.ConfigProto().GetChannelGroup())
+	if oldErr == nil && newErr == nil {
+		oldMessages, newMessages := map[string]proto.Message{}, map[string]proto.Message{}
+		for mspID, mspConfig := range oldMSPs {
+			oldMessages[mspID] = mspConfig
+		}
+		for mspID, mspConfig := range newMSPs {
+			newMessages[mspID] = mspConfig
+		}
+		added, removed, modified := diffMessages(oldMessages, newMessages)
+		add("msps_added", added)
+		add("msps_removed", removed)
+		add("msps_modified", modified)
+	}
+
+	oldPolicies, newPolicies := map[string]proto.Message{}, map[string]proto.Message{}
+	for path, policy := range collectPolicies(oldBundle.ConfigProto().GetChannelGroup()) {
+		oldPolicies[path] = policy
+	}
+	for path, policy := range collectPolicies(newBundle.ConfigProto().GetChannelGroup()) {
+		newPolicies[path] = policy
+	}
+	added, removed, modified := diffMessages(oldPolicies, newPolicies)
+	add("policies_added", added)
+	add("policies_removed", removed)
+	add("policies_modified", modified)
+
+	added, removed, _ = diffMessages(capabilityMessages(oldBundle), capabilityMessages(newBundle))
+	add("capabilities_added", added)
+	add("capabilities_removed", removed)
+
+	return kvPairs
+}
+
+// capabilityMessages returns the capabilities of the Channel, Orderer, and
+// Application sections of the bundle keyed by section and name, e.g.
+// Application/V2_0.
+func capabilityMessages(bundle *Bundle) map[string]proto.Message {
+	result := map[string]proto.Message{}
+	for _, section := range capabilitySections(bundle) {
+		for name, capability := range section.capabilities.GetCapabilities() {
+			result[section.name+"/"+name] = capability
+		}
+	}
+	return result
+}
+
+// diffMessages returns the sorted keys which are only in b, only in a, and in
+// both but with different messages.
+func diffMessages(a, b map[string]proto.Message) (added, removed, modified []string) {
+	for key, message := range a {
+		other, ok := b[key]
+		switch {
+		case !ok:
+			removed = append(removed, key)
+		case !proto.Equal(message, other):
+			modified = append(modified, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(modified)
+	return added, removed, modified
+}
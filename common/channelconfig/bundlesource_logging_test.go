@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/flogging/floggingtest"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestBundleSourceUpdateLogging(t *testing.T) {
+	initial := newTestBundleWithApplicationCapabilities(t, map[string]bool{"V1_4_2": true})
+
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	conf.Application.Capabilities = map[string]bool{"V2_0": true}
+	conf.Application.Policies["Readers"].Rule = "MAJORITY Readers"
+	next := newTestBundleFromProfile(t, conf)
+
+	t.Run("Debug", func(t *testing.T) {
+		logger, recorder := floggingtest.NewTestLogger(t)
+		bs := channelconfig.NewBundleSourceWithOptions(initial, channelconfig.WithUpdateLogging(logger))
+		require.Empty(t, recorder.Entries())
+
+		bs.Update(next)
+		require.Equal(t, []string{"Bundle updated", "Bundle update details"}, recorder.Messages())
+		require.Len(t, recorder.EntriesContaining("INFO"), 1)
+		require.Len(t, recorder.EntriesContaining(`channel=testchannel sequence=2 changes="changed sections: Application, Policies"`), 1)
+		require.Len(t, recorder.EntriesContaining("channel=testchannel sequence=2 policies_modified=[/Channel/Application/Readers] capabilities_added=[Application/V2_0] capabilities_removed=[Application/V1_4_2]"), 1)
+
+		recorder.Reset()
+		bs.Update(next)
+		require.Equal(t, []string{"Bundle updated"}, recorder.Messages())
+		require.Len(t, recorder.EntriesContaining("DEBU"), 1)
+		require.Len(t, recorder.EntriesContaining(`sequence=3 changes="no changes"`), 1)
+	})
+
+	t.Run("NilBundle", func(t *testing.T) {
+		logger, recorder := floggingtest.NewTestLogger(t)
+		bs := channelconfig.NewBundleSourceWithOptions(initial, channelconfig.WithUpdateLogging(logger))
+
+		bs.Update(nil)
+		require.Equal(t, []string{"Bundle updated"}, recorder.Messages())
+		require.Len(t, recorder.EntriesContaining("INFO"), 1)
+
+		// updates of a source without a bundle are not logged
+		recorder.Reset()
+		bs.Update(next)
+		require.Empty(t, recorder.Messages())
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		logger, recorder := floggingtest.NewTestLogger(t, floggingtest.AtLevel(zapcore.InfoLevel))
+		bs := channelconfig.NewBundleSourceWithOptions(initial, channelconfig.WithUpdateLogging(logger))
+
+		bs.Update(next)
+		require.Equal(t, []string{"Bundle updated"}, recorder.Messages())
+		require.Len(t, recorder.EntriesContaining(`changes="changed sections: Application, Policies"`), 1)
+
+		recorder.Reset()
+		bs.Update(next)
+		require.Empty(t, recorder.Messages())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(initial)
+		bs.Update(next)
+		require.True(t, bs.StableBundle() == next)
+	})
+}
@@ -0,0 +1,76 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/common/policies"
+)
+
+// OnOrgMembershipChange registers a function which is invoked on Update for
+// each of the Orderer, Application, and Consortiums groups, in this order,
+// whose set of orgs differs between the previous and the new bundle, with the
+// sorted names of the orgs the new bundle adds to and removes from the group.
+// Orgs of the Consortiums group are named as for OrgPolicyManager, i.e. by
+// consortium and org name separated by a slash.  Orgs are matched by name
+// only, so modifying an org is not a membership change, and a group which
+// only one of the bundles contains is treated as having no orgs.  The same
+// restrictions as for update listeners apply.
+func (bs *BundleSource) OnOrgMembershipChange(fn func(group string, added, removed []string)) {
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if oldBundle == nil {
+			return
+		}
+
+		oldChannelGroup := oldBundle.ConfigProto().GetChannelGroup()
+		newChannelGroup := newBundle.ConfigProto().GetChannelGroup()
+		for _, group := range []string{OrdererGroupKey, ApplicationGroupKey, ConsortiumsGroupKey} {
+			oldOrgs, newOrgs := groupOrgNames(oldChannelGroup, group), groupOrgNames(newChannelGroup, group)
+			added, removed := stringDifference(newOrgs, oldOrgs), stringDifference(oldOrgs, newOrgs)
+			if len(added) > 0 || len(removed) > 0 {
+				fn(group, added, removed)
+			}
+		}
+	})
+}
+
+// groupOrgNames returns the sorted names of the orgs of the given group of the
+// channel group.
+func groupOrgNames(channelGroup *cb.ConfigGroup, group string) []string {
+	configGroup := subGroup(channelGroup, group)
+	if configGroup == nil {
+		return nil
+	}
+	if group != ConsortiumsGroupKey {
+		return sortedGroupNames(configGroup.Groups)
+	}
+
+	var orgNames []string
+	for _, consortiumName := range sortedGroupNames(configGroup.Groups) {
+		for _, orgName := range sortedGroupNames(configGroup.Groups[consortiumName].Groups) {
+			orgNames = append(orgNames, consortiumName+policies.PathSeparator+orgName)
+		}
+	}
+	return orgNames
+}
+
+// stringDifference returns the elements of the sorted slice a which are not in
+// b, preserving their order.
+func stringDifference(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		inB[s] = true
+	}
+
+	var result []string
+	for _, s := range a {
+		if !inB[s] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceOnOrgMembershipChange(t *testing.T) {
+	loadConf := func() *genesisconfig.Profile {
+		return genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	}
+	// withOrgs returns the orgs with copies of the first one, renamed to the
+	// given names, appended if keep is set, or in their stead otherwise.
+	withOrgs := func(orgs []*genesisconfig.Organization, keep bool, names ...string) []*genesisconfig.Organization {
+		template := orgs[0]
+		if !keep {
+			orgs = nil
+		}
+		for _, name := range names {
+			org := *template
+			org.Name = name
+			orgs = append(orgs, &org)
+		}
+		return orgs
+	}
+
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, loadConf()))
+
+	type change struct {
+		group          string
+		added, removed []string
+	}
+	var changes []change
+	bs.OnOrgMembershipChange(func(group string, added, removed []string) {
+		changes = append(changes, change{group: group, added: added, removed: removed})
+	})
+
+	conf := loadConf()
+	conf.Orderer.BatchTimeout *= 2
+	conf.Application.Organizations[0].AnchorPeers = []*genesisconfig.AnchorPeer{{Host: "peer0.example.com", Port: 7051}}
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Empty(t, changes)
+
+	conf = loadConf()
+	conf.Application.Organizations = withOrgs(conf.Application.Organizations, true, "Org2", "Org1")
+	consortium := conf.Consortiums["SampleConsortium"]
+	consortium.Organizations = withOrgs(consortium.Organizations, true, "Org3")
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Equal(t, []change{
+		{group: channelconfig.ApplicationGroupKey, added: []string{"Org1", "Org2"}},
+		{group: channelconfig.ConsortiumsGroupKey, added: []string{"SampleConsortium/Org3"}},
+	}, changes)
+
+	changes = nil
+	conf = loadConf()
+	conf.Application.Organizations = withOrgs(conf.Application.Organizations, true, "Org1", "Org2")
+	consortium = conf.Consortiums["SampleConsortium"]
+	consortium.Organizations = withOrgs(consortium.Organizations, true, "Org3")
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Empty(t, changes)
+
+	conf = loadConf()
+	conf.Application.Organizations = withOrgs(conf.Application.Organizations, false, "Org4")
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Equal(t, []change{
+		{group: channelconfig.ApplicationGroupKey, added: []string{"Org4"}, removed: []string{"Org1", "Org2", "SampleOrg"}},
+		{group: channelconfig.ConsortiumsGroupKey, removed: []string{"SampleConsortium/Org3"}},
+	}, changes)
+
+	changes = nil
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	require.Equal(t, []change{
+		{group: channelconfig.OrdererGroupKey, removed: []string{"SampleOrg"}},
+		{group: channelconfig.ApplicationGroupKey, added: []string{"SampleOrg"}, removed: []string{"Org4"}},
+		{group: channelconfig.ConsortiumsGroupKey, removed: []string{"SampleConsortium/SampleOrg"}},
+	}, changes)
+}
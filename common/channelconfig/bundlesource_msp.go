@@ -0,0 +1,325 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// OrdererMSPs returns the MSPs of the orderer orgs keyed by MSP ID, and whether
+// the Orderer config exists.  All values are drawn from a single stable bundle.
+// An error is returned if an orderer org references an MSP ID which is not
+// defined in the MSP manager.
+func (bs *BundleSource) OrdererMSPs() (map[string]msp.MSP, bool, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false, err
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return nil, false, nil
+	}
+
+	msps, err := bundleMSPs(bundle)
+	if err != nil {
+		return nil, true, err
+	}
+
+	result, err := sectionMSPs(msps, "orderer", ordererOrgs(oc))
+	if err != nil {
+		return nil, true, err
+	}
+	return result, true, nil
+}
+
+// OrdererTLSCACerts returns the TLS root and intermediate CA certificates of
+// the MSPs of the orderer orgs of a single stable bundle, e.g. to build the TLS
+// configs of cluster communication.  The certificates are PEM encoded,
+// ordered by MSP ID and then by their order in the MSP definition, and each
+// distinct certificate is returned only once per category.  An error is
+// returned if the channel config has no Orderer group, or in the cases
+// OrdererMSPs returns one.  MSPs of a BundleSource created WithReducedMSP have
+// no TLS CAs.
+func (bs *BundleSource) OrdererTLSCACerts() (roots, intermediates [][]byte, err error) {
+	msps, ok, err := bs.OrdererMSPs()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, errors.Errorf("channel config has no %s group", OrdererGroupKey)
+	}
+
+	seenRoots, seenIntermediates := map[string]bool{}, map[string]bool{}
+	for _, mspID := range sortedMSPIDs(msps) {
+		roots = appendDistinctCerts(roots, seenRoots, msps[mspID].GetTLSRootCerts())
+		intermediates = appendDistinctCerts(intermediates, seenIntermediates, msps[mspID].GetTLSIntermediateCerts())
+	}
+	return roots, intermediates, nil
+}
+
+// appendDistinctCerts appends the certificates which are not yet in seen to
+// certs, and records them in seen.
+func appendDistinctCerts(certs [][]byte, seen map[string]bool, newCerts [][]byte) [][]byte {
+	for _, cert := range newCerts {
+		if seen[string(cert)] {
+			continue
+		}
+		seen[string(cert)] = true
+		certs = append(certs, cert)
+	}
+	return certs
+}
+
+// AllMSPs returns the MSPs of all orderer, application, and consortium orgs
+// of a single stable bundle, keyed by MSP ID.  It is the union of the maps
+// returned by MSPsBySection.
+func (bs *BundleSource) AllMSPs() (map[string]msp.MSP, error) {
+	orderer, application, consortiums, err := bs.MSPsBySection()
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]msp.MSP{}
+	for _, section := range []map[string]msp.MSP{orderer, application, consortiums} {
+		for mspID, sectionMSP := range section {
+			result[mspID] = sectionMSP
+		}
+	}
+	return result, nil
+}
+
+// MSPsBySection returns the MSPs of the orderer orgs, the application orgs,
+// and the orgs of all consortiums of a single stable bundle, each keyed by MSP
+// ID.  The map of a section is nil if the config does not contain it.  An
+// error is returned if an org references an MSP ID which is not defined in the
+// MSP manager.
+func (bs *BundleSource) MSPsBySection() (orderer, application, consortiums map[string]msp.MSP, err error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	msps, err := bundleMSPs(bundle)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if oc, ok := bundle.OrdererConfig(); ok {
+		if orderer, err = sectionMSPs(msps, "orderer", ordererOrgs(oc)); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if ac, ok := bundle.ApplicationConfig(); ok {
+		orgs := make(map[string]Org, len(ac.Organizations()))
+		for orgName, org := range ac.Organizations() {
+			orgs[orgName] = org
+		}
+		if application, err = sectionMSPs(msps, "application", orgs); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if cc, ok := bundle.ConsortiumsConfig(); ok {
+		consortiums = map[string]msp.MSP{}
+		for _, consortium := range cc.Consortiums() {
+			consortiumMSPs, err := sectionMSPs(msps, "consortium", consortium.Organizations())
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			for mspID, consortiumMSP := range consortiumMSPs {
+				consortiums[mspID] = consortiumMSP
+			}
+		}
+	}
+
+	return orderer, application, consortiums, nil
+}
+
+// SortedMSPIDs returns the IDs of the MSPs of the MSP manager of a single
+// stable bundle in sorted order, so that callers aggregating MSP data can
+// iterate over the MSPs deterministically.  It returns nil if the source is
+// not initialized or the bundle has no MSP manager.
+func (bs *BundleSource) SortedMSPIDs() []string {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil
+	}
+
+	msps, err := bundleMSPs(bundle)
+	if err != nil {
+		return nil
+	}
+	return sortedMSPIDs(msps)
+}
+
+// ExportMSPConfigs returns the definitions of the Fabric MSPs of the orgs in
+// the given group, i.e. the Orderer, the Application, or the Consortiums group,
+// of a single stable bundle, keyed by MSP ID, e.g. to distribute them to nodes
+// joining the channel.  For the Consortiums group, the MSPs of the orgs of all
+// consortiums are returned.  Only the public parts of the definitions are
+// returned: should a definition carry a signing identity, it is discarded.
+// MSPs which are not Fabric MSPs are omitted.  An error is returned if the
+// group does not contain orgs or is not part of the config.
+func (bs *BundleSource) ExportMSPConfigs(group string) (map[string]*mspprotos.FabricMSPConfig, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	switch group {
+	case OrdererGroupKey, ApplicationGroupKey, ConsortiumsGroupKey:
+	default:
+		return nil, errors.Errorf("group %s does not contain orgs", group)
+	}
+	configGroup := subGroup(bundle.ConfigProto().GetChannelGroup(), group)
+	if configGroup == nil {
+		return nil, errors.Errorf("channel config has no %s group", group)
+	}
+
+	mspConfigs, err := collectMSPConfigs(configGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*mspprotos.FabricMSPConfig, len(mspConfigs))
+	for mspID, mspConfig := range mspConfigs {
+		if mspConfig.Type != int32(msp.FABRIC) {
+			continue
+		}
+		fabricConfig := &mspprotos.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal fabric MSP config of MSP %s", mspID)
+		}
+		fabricConfig.SigningIdentity = nil
+		result[mspID] = fabricConfig
+	}
+	return result, nil
+}
+
+// MSPCounts returns the number of distinct MSP IDs referenced by the orderer,
+// application, and consortium orgs of a single stable bundle, and the number
+// of distinct MSP IDs referenced by any org, e.g. to watch the growth of
+// channel configs.  The consortiums count covers the orgs of all consortiums.
+// As an org may be a member of several groups, total may be less than the sum
+// of the per-group counts.  All counts are zero if the BundleSource has not
+// been initialized.
+func (bs *BundleSource) MSPCounts() (orderer, application, consortiums, total int) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return 0, 0, 0, 0
+	}
+
+	mspIDs := map[string]map[string]struct{}{
+		OrdererGroupKey:     {},
+		ApplicationGroupKey: {},
+		ConsortiumsGroupKey: {},
+	}
+	all := map[string]struct{}{}
+	bundle.walkOrgs(func(group, consortiumName string, org Org) {
+		mspIDs[group][org.MSPID()] = struct{}{}
+		all[org.MSPID()] = struct{}{}
+	})
+
+	return len(mspIDs[OrdererGroupKey]), len(mspIDs[ApplicationGroupKey]), len(mspIDs[ConsortiumsGroupKey]), len(all)
+}
+
+// bundleMSPs returns the MSPs of the MSP manager of the bundle, keyed by MSP
+// ID.
+func bundleMSPs(bundle *Bundle) (map[string]msp.MSP, error) {
+	mspManager := bundle.MSPManager()
+	if mspManager == nil {
+		return nil, errors.New("bundle has no MSP manager")
+	}
+	msps, err := mspManager.GetMSPs()
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not retrieve MSPs from MSP manager")
+	}
+	return msps, nil
+}
+
+// sortedMSPIDs returns the keys of the given MSPs in sorted order.  Code
+// serializing or hashing MSP data must iterate over the MSPs in this order.
+func sortedMSPIDs(msps map[string]msp.MSP) []string {
+	mspIDs := make([]string, 0, len(msps))
+	for mspID := range msps {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+	return mspIDs
+}
+
+func ordererOrgs(oc Orderer) map[string]Org {
+	orgs := make(map[string]Org, len(oc.Organizations()))
+	for orgName, org := range oc.Organizations() {
+		orgs[orgName] = org
+	}
+	return orgs
+}
+
+// sectionMSPs looks up the MSPs of the given orgs, keyed by MSP ID.
+func sectionMSPs(msps map[string]msp.MSP, section string, orgs map[string]Org) (map[string]msp.MSP, error) {
+	result := make(map[string]msp.MSP, len(orgs))
+	for orgName, org := range orgs {
+		mspID := org.MSPID()
+		orgMSP, ok := msps[mspID]
+		if !ok {
+			return nil, errors.Errorf("%s org %s references unknown MSP ID %s", section, orgName, mspID)
+		}
+		result[mspID] = orgMSP
+	}
+	return result, nil
+}
+
+// ValidateIdentity deserializes the given identity and validates it against
+// the MSP manager of a single stable bundle, returning the validated identity.
+func (bs *BundleSource) ValidateIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+	return validateIdentity(bundle, serializedIdentity)
+}
+
+// ValidateIdentityAtUpdate behaves like ValidateIdentity, but fails if the
+// sequence of the BundleSource, see Sequence, is not the given one, allowing
+// callers to detect that the bundle was replaced since they obtained the
+// sequence.  The identity is validated against the bundle of that sequence.
+func (bs *BundleSource) ValidateIdentityAtUpdate(serializedIdentity []byte, updateSeq uint64) (msp.Identity, error) {
+	current, err := bs.load()
+	if err != nil {
+		return nil, err
+	}
+	if current.sequence != updateSeq {
+		return nil, errors.Errorf("bundle source sequence is %d, expected %d", current.sequence, updateSeq)
+	}
+	return validateIdentity(current.bundle, serializedIdentity)
+}
+
+func validateIdentity(bundle *Bundle, serializedIdentity []byte) (msp.Identity, error) {
+	mspManager := bundle.MSPManager()
+	if mspManager == nil {
+		return nil, errors.New("bundle has no MSP manager")
+	}
+
+	identity, err := mspManager.DeserializeIdentity(serializedIdentity)
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not deserialize identity")
+	}
+
+	if err := identity.Validate(); err != nil {
+		return nil, errors.WithMessage(err, "identity is not valid")
+	}
+
+	return identity, nil
+}
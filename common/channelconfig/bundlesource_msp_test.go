@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdererMSPsUnknownMSPID(t *testing.T) {
+	mspManager := msp.NewMSPManager()
+	require.NoError(t, mspManager.Setup(nil))
+
+	bs := NewBundleSource(&Bundle{
+		channelConfig: &ChannelConfig{
+			mspManager: mspManager,
+			ordererConfig: &OrdererConfig{
+				orgs: map[string]OrdererOrg{
+					"org1": &OrdererOrgConfig{OrganizationConfig: &OrganizationConfig{name: "org1", mspID: "org1msp"}},
+				},
+			},
+		},
+	})
+
+	msps, ok, err := bs.OrdererMSPs()
+	require.EqualError(t, err, "orderer org org1 references unknown MSP ID org1msp")
+	require.True(t, ok)
+	require.Nil(t, msps)
+}
+
+func TestMSPsBySectionUnknownMSPID(t *testing.T) {
+	mspManager := msp.NewMSPManager()
+	require.NoError(t, mspManager.Setup(nil))
+
+	bs := NewBundleSource(&Bundle{
+		channelConfig: &ChannelConfig{
+			mspManager: mspManager,
+			appConfig: &ApplicationConfig{
+				applicationOrgs: map[string]ApplicationOrg{
+					"org1": &ApplicationOrgConfig{OrganizationConfig: &OrganizationConfig{name: "org1", mspID: "org1msp"}},
+				},
+			},
+		},
+	})
+
+	orderer, application, consortiums, err := bs.MSPsBySection()
+	require.EqualError(t, err, "application org org1 references unknown MSP ID org1msp")
+	require.Nil(t, orderer)
+	require.Nil(t, application)
+	require.Nil(t, consortiums)
+
+	msps, err := bs.AllMSPs()
+	require.EqualError(t, err, "application org org1 references unknown MSP ID org1msp")
+	require.Nil(t, msps)
+}
+
+func TestBundleSourceMSPCounts(t *testing.T) {
+	newOrg := func(name string) *OrganizationConfig {
+		return &OrganizationConfig{name: name, mspID: name + "MSP"}
+	}
+	bs := NewBundleSource(&Bundle{
+		channelConfig: &ChannelConfig{
+			ordererConfig: &OrdererConfig{
+				orgs: map[string]OrdererOrg{
+					"OrdererOrg": &OrdererOrgConfig{OrganizationConfig: newOrg("OrdererOrg")},
+				},
+			},
+			appConfig: &ApplicationConfig{
+				applicationOrgs: map[string]ApplicationOrg{
+					"Org1": &ApplicationOrgConfig{OrganizationConfig: newOrg("Org1")},
+					"Org2": &ApplicationOrgConfig{OrganizationConfig: newOrg("Org2")},
+				},
+			},
+			consortiumsConfig: &ConsortiumsConfig{
+				consortiums: map[string]Consortium{
+					"Consortium1": &ConsortiumConfig{orgs: map[string]Org{"Org1": newOrg("Org1"), "Org3": newOrg("Org3")}},
+					"Consortium2": &ConsortiumConfig{orgs: map[string]Org{"Org3": newOrg("Org3")}},
+				},
+			},
+		},
+	})
+
+	orderer, application, consortiums, total := bs.MSPCounts()
+	require.Equal(t, 1, orderer)
+	require.Equal(t, 2, application)
+	require.Equal(t, 2, consortiums)
+	require.Equal(t, 4, total)
+
+	orderer, application, consortiums, total = (&BundleSource{}).MSPCounts()
+	require.Zero(t, orderer+application+consortiums+total)
+}
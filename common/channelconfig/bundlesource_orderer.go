@@ -0,0 +1,296 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// ConsensusType returns the consensus type of the current bundle and whether
+// the Orderer config exists.
+func (bs *BundleSource) ConsensusType() (string, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return "", false
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return "", false
+	}
+	return oc.ConsensusType(), true
+}
+
+// OnConsensusTypeChange registers a function which is invoked on Update
+// whenever the consensus type of the new bundle differs from the one of the
+// previous bundle.  A missing Orderer config is reported as the empty
+// consensus type.  The same restrictions as for update listeners apply.
+func (bs *BundleSource) OnConsensusTypeChange(fn func(oldType, newType string)) {
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if oldBundle == nil {
+			return
+		}
+		oldType, newType := consensusType(oldBundle), consensusType(newBundle)
+		if oldType != newType {
+			fn(oldType, newType)
+		}
+	})
+}
+
+func consensusType(bundle *Bundle) string {
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return ""
+	}
+	return oc.ConsensusType()
+}
+
+// ConsensusMetadata returns a copy of the consensus metadata of the current
+// bundle and whether the Orderer config exists.
+func (bs *BundleSource) ConsensusMetadata() ([]byte, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return nil, false
+	}
+	return oc.ConsensusMetadata(), true
+}
+
+// RaftMetadata returns the consensus metadata of the current bundle
+// unmarshaled as etcdraft metadata.  The consensus type and the metadata are
+// read from the same bundle.  ErrNoOrdererConfig is returned if the bundle has
+// no Orderer config, and an error if its consensus type is not etcdraft.
+func (bs *BundleSource) RaftMetadata() (*etcdraft.ConfigMetadata, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return nil, ErrNoOrdererConfig
+	}
+	if oc.ConsensusType() != "etcdraft" {
+		return nil, errors.Errorf("consensus type is %s, not etcdraft", oc.ConsensusType())
+	}
+
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(oc.ConsensusMetadata(), metadata); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+	}
+	return metadata, nil
+}
+
+// OrdererOrgCount returns the number of orderer orgs of the current bundle and
+// whether the Orderer config exists.
+func (bs *BundleSource) OrdererOrgCount() (int, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return 0, false
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return 0, false
+	}
+	return len(oc.Organizations()), true
+}
+
+// MaxChannels returns the maximum number of channels the ordering service
+// allows, as set by the channel restrictions of the current bundle, and
+// whether such a limit is set.  The limit is not set if the Orderer config or
+// its channel restrictions are missing, or if the maximum count is zero, each
+// of which means that the number of channels is unlimited.
+func (bs *BundleSource) MaxChannels() (uint64, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return 0, false
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return 0, false
+	}
+	maxCount := oc.MaxChannelsCount()
+	return maxCount, maxCount != 0
+}
+
+// BatchSize returns the batch size of the current bundle and whether the
+// Orderer config exists.
+func (bs *BundleSource) BatchSize() (*ab.BatchSize, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return nil, false
+	}
+	return oc.BatchSize(), true
+}
+
+// MaxMessageCount returns the maximum number of messages per block, as set by
+// the batch size of the current bundle, and whether the Orderer config exists.
+func (bs *BundleSource) MaxMessageCount() (uint32, bool) {
+	batchSize, ok := bs.BatchSize()
+	return batchSize.GetMaxMessageCount(), ok
+}
+
+// AbsoluteMaxBytes returns the maximum size of the serialized messages of a
+// block, as set by the batch size of the current bundle, and whether the
+// Orderer config exists.
+func (bs *BundleSource) AbsoluteMaxBytes() (uint32, bool) {
+	batchSize, ok := bs.BatchSize()
+	return batchSize.GetAbsoluteMaxBytes(), ok
+}
+
+// PreferredMaxBytes returns the preferred size of the serialized messages of a
+// block, as set by the batch size of the current bundle, and whether the
+// Orderer config exists.
+func (bs *BundleSource) PreferredMaxBytes() (uint32, bool) {
+	batchSize, ok := bs.BatchSize()
+	return batchSize.GetPreferredMaxBytes(), ok
+}
+
+// BatchTimeout returns the batch timeout of the current bundle and whether
+// the Orderer config exists.
+func (bs *BundleSource) BatchTimeout() (time.Duration, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return 0, false
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return 0, false
+	}
+	return oc.BatchTimeout(), true
+}
+
+// OnBatchConfigChange registers a function which is invoked on Update with
+// the batch size and batch timeout of the new bundle, whenever either of them
+// differs from the previous bundle.  It is not invoked if the new bundle has
+// no Orderer config.  The same restrictions as for update listeners apply.
+func (bs *BundleSource) OnBatchConfigChange(fn func(batchSize *ab.BatchSize, batchTimeout time.Duration)) {
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if oldBundle == nil {
+			return
+		}
+
+		noc, ok := newBundle.OrdererConfig()
+		if !ok {
+			return
+		}
+
+		if ooc, ok := oldBundle.OrdererConfig(); ok &&
+			proto.Equal(ooc.BatchSize(), noc.BatchSize()) &&
+			ooc.BatchTimeout() == noc.BatchTimeout() {
+			return
+		}
+
+		fn(noc.BatchSize(), noc.BatchTimeout())
+	})
+}
+
+// OrdererEndpoints returns the global orderer addresses of the current bundle,
+// as defined by the OrdererAddresses value of the channel group.  Consumers of
+// the ordering service, such as the peer's orderer connection source, only
+// fall back to these addresses if no orderer org defines its own endpoints,
+// see OrdererEndpointsByOrg.
+func (bs *BundleSource) OrdererEndpoints() []string {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil
+	}
+
+	cc := bundle.ChannelConfig()
+	if cc == nil {
+		return nil
+	}
+	addresses := cc.OrdererAddresses()
+	if len(addresses) == 0 {
+		return nil
+	}
+	return addresses
+}
+
+// OrdererEndpointsByOrg returns the endpoints of the orderer orgs of the
+// current bundle, keyed by org name.  Orgs without endpoints are omitted.
+// Org specific endpoints require the V1_4_2 channel capability and take
+// precedence over the global addresses returned by OrdererEndpoints whenever
+// any org defines them.
+func (bs *BundleSource) OrdererEndpointsByOrg() map[string][]string {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return nil
+	}
+
+	result := map[string][]string{}
+	for orgName, org := range oc.Organizations() {
+		if endpoints := org.Endpoints(); len(endpoints) > 0 {
+			result[orgName] = endpoints
+		}
+	}
+	return result
+}
+
+// OrdererEndpointMap returns the org specific endpoints of the orderer orgs of
+// the current bundle, mapped to the MSP ID of the org defining them, and
+// whether the Orderer config exists.  Global orderer addresses, which belong
+// to no org, are not included.  As an endpoint defined by several orgs has an
+// ambiguous TLS identity, it causes an error.
+func (bs *BundleSource) OrdererEndpointMap() (map[string]string, bool, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false, err
+	}
+
+	oc, ok := bundle.OrdererConfig()
+	if !ok {
+		return nil, false, nil
+	}
+
+	result := map[string]string{}
+	owners := map[string]string{}
+	orgs := oc.Organizations()
+	for _, orgName := range sortedOrgNames(orgs) {
+		org := orgs[orgName]
+		for _, endpoint := range org.Endpoints() {
+			if owner, ok := owners[endpoint]; ok && owner != orgName {
+				return nil, true, errors.Errorf("orderer endpoint %s is defined by both org %s and org %s", endpoint, owner, orgName)
+			}
+			owners[endpoint] = orgName
+			result[endpoint] = org.MSPID()
+		}
+	}
+	return result, true, nil
+}
+
+func sortedOrgNames(orgs map[string]OrdererOrg) []string {
+	names := make([]string, 0, len(orgs))
+	for name := range orgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,240 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+	"time"
+
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceConsensusType(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	var changes [][2]string
+	bs.OnConsensusTypeChange(func(oldType, newType string) {
+		changes = append(changes, [2]string{oldType, newType})
+	})
+
+	consensusType, ok := bs.ConsensusType()
+	require.True(t, ok)
+	require.Equal(t, "solo", consensusType)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.Empty(t, changes)
+
+	bs.Update(newTestRaftBundle(t))
+	require.Equal(t, [][2]string{{"solo", "etcdraft"}}, changes)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	require.Equal(t, [][2]string{{"solo", "etcdraft"}, {"etcdraft", ""}}, changes)
+
+	_, ok = bs.ConsensusType()
+	require.False(t, ok)
+}
+
+func TestBundleSourceConsensusMetadata(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestRaftBundle(t))
+
+	metadata, ok := bs.ConsensusMetadata()
+	require.True(t, ok)
+	require.NotEmpty(t, metadata)
+
+	raftMetadata, err := bs.RaftMetadata()
+	require.NoError(t, err)
+	require.Len(t, raftMetadata.Consenters, 3)
+	require.NotNil(t, raftMetadata.Options)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	_, ok = bs.ConsensusMetadata()
+	require.True(t, ok)
+	_, err = bs.RaftMetadata()
+	require.EqualError(t, err, "consensus type is solo, not etcdraft")
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	_, ok = bs.ConsensusMetadata()
+	require.False(t, ok)
+	_, err = bs.RaftMetadata()
+	require.Equal(t, channelconfig.ErrNoOrdererConfig, err)
+
+	_, err = (&channelconfig.BundleSource{}).RaftMetadata()
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceBatchSizeLimits(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.BatchSize.MaxMessageCount = 10
+	conf.Orderer.BatchSize.AbsoluteMaxBytes = 4 * 1024 * 1024
+	conf.Orderer.BatchSize.PreferredMaxBytes = 1024 * 1024
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+
+	maxMessageCount, ok := bs.MaxMessageCount()
+	require.True(t, ok)
+	require.Equal(t, uint32(10), maxMessageCount)
+	absoluteMaxBytes, ok := bs.AbsoluteMaxBytes()
+	require.True(t, ok)
+	require.Equal(t, uint32(4*1024*1024), absoluteMaxBytes)
+	preferredMaxBytes, ok := bs.PreferredMaxBytes()
+	require.True(t, ok)
+	require.Equal(t, uint32(1024*1024), preferredMaxBytes)
+
+	for _, bs := range []*channelconfig.BundleSource{
+		channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile)),
+		{},
+	} {
+		_, ok = bs.MaxMessageCount()
+		require.False(t, ok)
+		_, ok = bs.AbsoluteMaxBytes()
+		require.False(t, ok)
+		_, ok = bs.PreferredMaxBytes()
+		require.False(t, ok)
+	}
+}
+
+func TestBundleSourceMaxChannels(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.MaxChannels = 100
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+
+	maxChannels, ok := bs.MaxChannels()
+	require.True(t, ok)
+	require.Equal(t, uint64(100), maxChannels)
+
+	for _, bs := range []*channelconfig.BundleSource{
+		channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)),
+		channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile)),
+		{},
+	} {
+		maxChannels, ok = bs.MaxChannels()
+		require.False(t, ok)
+		require.Zero(t, maxChannels)
+	}
+}
+
+func TestBundleSourceBatchConfig(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	type batchConfig struct {
+		batchSize    *ab.BatchSize
+		batchTimeout time.Duration
+	}
+	var changes []batchConfig
+	bs.OnBatchConfigChange(func(batchSize *ab.BatchSize, batchTimeout time.Duration) {
+		changes = append(changes, batchConfig{batchSize: batchSize, batchTimeout: batchTimeout})
+	})
+
+	batchSize, ok := bs.BatchSize()
+	require.True(t, ok)
+	require.Equal(t, uint32(500), batchSize.MaxMessageCount)
+	batchTimeout, ok := bs.BatchTimeout()
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, batchTimeout)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.Empty(t, changes)
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.BatchTimeout = 5 * time.Second
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Len(t, changes, 1)
+	require.Equal(t, 5*time.Second, changes[0].batchTimeout)
+
+	conf.Orderer.BatchSize.MaxMessageCount = 10
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Len(t, changes, 2)
+	require.Equal(t, uint32(10), changes[1].batchSize.MaxMessageCount)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	require.Len(t, changes, 2)
+	_, ok = bs.BatchSize()
+	require.False(t, ok)
+	_, ok = bs.BatchTimeout()
+	require.False(t, ok)
+}
+
+func TestBundleSourceOrdererEndpoints(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+	require.Nil(t, bs.OrdererEndpoints())
+	require.Equal(t, map[string][]string{"SampleOrg": {"127.0.0.1:7050"}}, bs.OrdererEndpointsByOrg())
+
+	conf.Orderer.Addresses = []string{"orderer0:7050", "orderer1:7050"}
+	conf.Orderer.Organizations[0].OrdererEndpoints = nil
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Equal(t, []string{"orderer0:7050", "orderer1:7050"}, bs.OrdererEndpoints())
+	require.Empty(t, bs.OrdererEndpointsByOrg())
+
+	bs = &channelconfig.BundleSource{}
+	require.Nil(t, bs.OrdererEndpoints())
+	require.Nil(t, bs.OrdererEndpointsByOrg())
+}
+
+func TestBundleSourceOrdererEndpointMap(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	otherOrg := *conf.Orderer.Organizations[0]
+	otherOrg.Name = "OtherOrg"
+	otherOrg.ID = "OtherMSP"
+	otherOrg.OrdererEndpoints = []string{"orderer1:7050", "orderer2:7050"}
+	conf.Orderer.Organizations = append(conf.Orderer.Organizations, &otherOrg)
+	conf.Orderer.Addresses = []string{"global:7050"}
+
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+	endpoints, ok, err := bs.OrdererEndpointMap()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{
+		"127.0.0.1:7050": "SampleOrg",
+		"orderer1:7050":  "OtherMSP",
+		"orderer2:7050":  "OtherMSP",
+	}, endpoints)
+
+	otherOrg.OrdererEndpoints = []string{"orderer1:7050", "127.0.0.1:7050"}
+	bs.Update(newTestBundleFromProfile(t, conf))
+	_, ok, err = bs.OrdererEndpointMap()
+	require.EqualError(t, err, "orderer endpoint 127.0.0.1:7050 is defined by both org OtherOrg and org SampleOrg")
+	require.True(t, ok)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	endpoints, ok, err = bs.OrdererEndpointMap()
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Nil(t, endpoints)
+
+	_, _, err = (&channelconfig.BundleSource{}).OrdererEndpointMap()
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceOrdererOrgCount(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+	count, ok := bs.OrdererOrgCount()
+	require.True(t, ok)
+	require.Equal(t, 1, count)
+
+	otherOrg := *conf.Orderer.Organizations[0]
+	otherOrg.Name = "OtherOrg"
+	otherOrg.ID = "OtherMSP"
+	otherOrg.OrdererEndpoints = []string{"orderer1:7050"}
+	conf.Orderer.Organizations = append(conf.Orderer.Organizations, &otherOrg)
+	bs.Update(newTestBundleFromProfile(t, conf))
+	count, ok = bs.OrdererOrgCount()
+	require.True(t, ok)
+	require.Equal(t, 2, count)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	count, ok = bs.OrdererOrgCount()
+	require.False(t, ok)
+	require.Zero(t, count)
+
+	_, ok = (&channelconfig.BundleSource{}).OrdererOrgCount()
+	require.False(t, ok)
+}
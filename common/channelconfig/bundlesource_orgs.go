@@ -0,0 +1,40 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+// OrgInfo describes an org of the channel config.  Group is OrdererGroupKey,
+// ApplicationGroupKey, or ConsortiumsGroupKey, and ConsortiumName is only set
+// for orgs of the Consortiums group.
+type OrgInfo struct {
+	Name           string
+	Group          string
+	ConsortiumName string
+	MSPID          string
+}
+
+// Organizations returns the orderer, application, and consortium orgs of a
+// single stable bundle, in this order.  Consortium orgs are sorted by
+// consortium name, and the orgs of each group or consortium by name.  An org
+// which is a member of several groups or consortiums is listed once for each
+// of them.  No orgs are returned if the BundleSource has not been initialized.
+func (bs *BundleSource) Organizations() []OrgInfo {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil
+	}
+
+	var result []OrgInfo
+	bundle.walkOrgs(func(group, consortiumName string, org Org) {
+		result = append(result, OrgInfo{
+			Name:           org.Name(),
+			Group:          group,
+			ConsortiumName: consortiumName,
+			MSPID:          org.MSPID(),
+		})
+	})
+	return result
+}
@@ -0,0 +1,38 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceOrganizations(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	otherOrg := *conf.Application.Organizations[0]
+	otherOrg.Name = "OtherOrg"
+	otherOrg.ID = "OtherOrgMSP"
+	conf.Application.Organizations = append(conf.Application.Organizations, &otherOrg)
+	conf.Consortiums["OtherConsortium"] = &genesisconfig.Consortium{
+		Organizations: []*genesisconfig.Organization{&otherOrg},
+	}
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+
+	require.Equal(t, []channelconfig.OrgInfo{
+		{Name: "SampleOrg", Group: channelconfig.OrdererGroupKey, MSPID: "SampleOrg"},
+		{Name: "OtherOrg", Group: channelconfig.ApplicationGroupKey, MSPID: "OtherOrgMSP"},
+		{Name: "SampleOrg", Group: channelconfig.ApplicationGroupKey, MSPID: "SampleOrg"},
+		{Name: "OtherOrg", Group: channelconfig.ConsortiumsGroupKey, ConsortiumName: "OtherConsortium", MSPID: "OtherOrgMSP"},
+		{Name: "SampleOrg", Group: channelconfig.ConsortiumsGroupKey, ConsortiumName: "SampleConsortium", MSPID: "SampleOrg"},
+	}, bs.Organizations())
+
+	require.Nil(t, (&channelconfig.BundleSource{}).Organizations())
+}
@@ -0,0 +1,259 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// EvaluatePolicy resolves the named policy and evaluates it against the given
+// signature set, using the policy manager of a single stable bundle.  It
+// returns a *PolicyNotFoundError if the policy does not exist, and a
+// *PolicyDeniedError if the signature set does not satisfy the policy.
+func (bs *BundleSource) EvaluatePolicy(policyName string, signatureSet []*protoutil.SignedData) error {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return err
+	}
+	return evaluatePolicy(bundle.PolicyManager(), policyName, signatureSet)
+}
+
+func evaluatePolicy(policyManager policies.Manager, policyName string, signatureSet []*protoutil.SignedData) error {
+	policy, ok := policyManager.GetPolicy(policyName)
+	if !ok {
+		return &PolicyNotFoundError{PolicyName: policyName}
+	}
+
+	if err := policy.EvaluateSignedData(signatureSet); err != nil {
+		return &PolicyDeniedError{PolicyName: policyName, Err: err}
+	}
+
+	return nil
+}
+
+// CanRead deserializes and validates the serialized identity and evaluates
+// the channel Readers policy against it, using a single stable bundle.  It
+// returns nil if the identity may read from the channel, and a
+// *PolicyDeniedError otherwise, including when the identity is not valid.
+func (bs *BundleSource) CanRead(identity []byte) error {
+	return bs.evaluateIdentity(policies.ChannelReaders, identity)
+}
+
+// CanWrite behaves like CanRead, but evaluates the channel Writers policy.
+func (bs *BundleSource) CanWrite(identity []byte) error {
+	return bs.evaluateIdentity(policies.ChannelWriters, identity)
+}
+
+func (bs *BundleSource) evaluateIdentity(policyName string, serializedIdentity []byte) error {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return err
+	}
+
+	policy, ok := bundle.PolicyManager().GetPolicy(policyName)
+	if !ok {
+		return &PolicyNotFoundError{PolicyName: policyName}
+	}
+
+	identity, err := validateIdentity(bundle, serializedIdentity)
+	if err != nil {
+		return &PolicyDeniedError{PolicyName: policyName, Err: err}
+	}
+	if err := policy.EvaluateIdentities([]msp.Identity{identity}); err != nil {
+		return &PolicyDeniedError{PolicyName: policyName, Err: err}
+	}
+	return nil
+}
+
+// ResolvePolicy resolves a slash delimited policy path such as
+// /Channel/Application/Org1/Writers by descending the policy manager hierarchy
+// of a single stable bundle, and returns the policy and whether it exists.
+// Leading, trailing, and repeated slashes are ignored, and the leading Channel
+// segment may be omitted, so Application/Org1/Writers resolves to the same
+// policy.
+func (bs *BundleSource) ResolvePolicy(path string) (policies.Policy, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+	return resolvePolicy(bundle.PolicyManager(), path)
+}
+
+func resolvePolicy(policyManager policies.Manager, path string) (policies.Policy, bool) {
+	segments := policyPathSegments(path)
+	if len(segments) == 0 {
+		return nil, false
+	}
+
+	manager, ok := policyManager.Manager(segments[:len(segments)-1])
+	if !ok {
+		return nil, false
+	}
+
+	policy, ok := manager.GetPolicy(segments[len(segments)-1])
+	if !ok {
+		return nil, false
+	}
+	return policy, true
+}
+
+// policyPathSegments splits a policy path as accepted by ResolvePolicy into
+// its segments below the Channel group.
+func policyPathSegments(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, policies.PathSeparator) {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) > 0 && segments[0] == RootGroupKey {
+		segments = segments[1:]
+	}
+	return segments
+}
+
+// OrgPolicyManager returns the policy manager of the named org in the given
+// group, which is one of Application, Orderer, or Consortiums, as found in a
+// single stable bundle.  As orgs of the Consortiums group are defined per
+// consortium, their name must be given as Consortium/Org.  It returns false if
+// the group or the org does not exist.
+func (bs *BundleSource) OrgPolicyManager(group, orgName string) (policies.Manager, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, false
+	}
+
+	path, ok := orgGroupPath(group, orgName)
+	if !ok {
+		return nil, false
+	}
+	return bundle.PolicyManager().Manager(path)
+}
+
+// orgGroupPath returns the path below the Channel group of the group of the
+// named org in the given group, named as accepted by OrgPolicyManager, and
+// whether the group and the org name are well formed.
+func orgGroupPath(group, orgName string) ([]string, bool) {
+	path := []string{group}
+	switch group {
+	case ApplicationGroupKey, OrdererGroupKey:
+		path = append(path, orgName)
+	case ConsortiumsGroupKey:
+		segments := strings.Split(orgName, policies.PathSeparator)
+		if len(segments) != 2 {
+			return nil, false
+		}
+		path = append(path, segments...)
+	default:
+		return nil, false
+	}
+
+	for _, segment := range path {
+		if segment == "" {
+			return nil, false
+		}
+	}
+	return path, true
+}
+
+// ChannelModPolicy returns the mod_policy of the channel group, i.e. the
+// policy governing modifications of the channel config, and whether the
+// current bundle was built from a config.
+func (bs *BundleSource) ChannelModPolicy() (string, bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return "", false
+	}
+	channelGroup := bundle.ConfigProto().GetChannelGroup()
+	if channelGroup == nil {
+		return "", false
+	}
+	return channelGroup.ModPolicy, true
+}
+
+// ModPolicyFor returns the mod_policy of the given group, which is one of
+// Orderer, Application, or Consortiums, and whether the group exists in the
+// config of the current bundle.
+func (bs *BundleSource) ModPolicyFor(group string) (string, bool) {
+	switch group {
+	case OrdererGroupKey, ApplicationGroupKey, ConsortiumsGroupKey:
+	default:
+		return "", false
+	}
+
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return "", false
+	}
+	configGroup, ok := bundle.ConfigProto().GetChannelGroup().GetGroups()[group]
+	if !ok {
+		return "", false
+	}
+	return configGroup.ModPolicy, true
+}
+
+// WalkPolicies invokes fn for every policy defined in the config of a single
+// stable bundle, with the absolute path of the policy, e.g.
+// /Channel/Application/Writers, and the policy as returned by ResolvePolicy.
+// The policies are visited depth first in order of their paths, so the order
+// is deterministic.  If fn returns an error, the walk stops and the error is
+// returned.
+func (bs *BundleSource) WalkPolicies(fn func(path string, policy policies.Policy) error) error {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return err
+	}
+
+	configPolicies := collectPolicies(bundle.ConfigProto().GetChannelGroup())
+	paths := make([]string, 0, len(configPolicies))
+	for path := range configPolicies {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		policy, ok := resolvePolicy(bundle.PolicyManager(), path)
+		if !ok {
+			return errors.Errorf("policy %s is not known to the policy manager", path)
+		}
+		if err := fn(path, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImplicitMetaRule returns the rule, one of ANY, ALL, or MAJORITY, and the
+// name of the sub-policy aggregated by the implicit meta policy at the given
+// path, which is resolved like ResolvePolicy does, as defined in the config of
+// a single stable bundle.  It returns false if the path does not refer to an
+// implicit meta policy, e.g. because it refers to a signature policy.
+func (bs *BundleSource) ImplicitMetaRule(path string) (rule string, subPolicy string, ok bool) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return "", "", false
+	}
+
+	_, configPolicy := lookupConfigPolicy(bundle.ConfigProto(), policyPathSegments(path))
+	if configPolicy.GetPolicy().GetType() != int32(cb.Policy_IMPLICIT_META) {
+		return "", "", false
+	}
+
+	definition := &cb.ImplicitMetaPolicy{}
+	if err := proto.Unmarshal(configPolicy.Policy.Value, definition); err != nil {
+		return "", "", false
+	}
+	return definition.Rule.String(), definition.SubPolicy, true
+}
@@ -0,0 +1,254 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceEvaluatePolicy(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	t.Run("NotFound", func(t *testing.T) {
+		err := bs.EvaluatePolicy("/Channel/Missing", nil)
+		require.EqualError(t, err, "policy /Channel/Missing not found")
+		notFound := &channelconfig.PolicyNotFoundError{}
+		require.True(t, errors.As(err, &notFound))
+		require.Equal(t, "/Channel/Missing", notFound.PolicyName)
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		err := bs.EvaluatePolicy("/Channel/Orderer/BlockValidation", nil)
+		require.Error(t, err)
+		denied := &channelconfig.PolicyDeniedError{}
+		require.True(t, errors.As(err, &denied))
+		require.Equal(t, "/Channel/Orderer/BlockValidation", denied.PolicyName)
+		require.Error(t, denied.Unwrap())
+	})
+}
+
+func TestBundleSourceCanReadWrite(t *testing.T) {
+	identity := newTestSignedData(t, []byte("data")).Identity
+
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.NoError(t, bs.CanRead(identity))
+	require.NoError(t, bs.CanWrite(identity))
+
+	t.Run("InvalidIdentity", func(t *testing.T) {
+		err := bs.CanWrite([]byte("garbage"))
+		denied := &channelconfig.PolicyDeniedError{}
+		require.True(t, errors.As(err, &denied))
+		require.Equal(t, policies.ChannelWriters, denied.PolicyName)
+		require.Contains(t, err.Error(), "could not deserialize identity")
+	})
+
+	t.Run("UnknownMSP", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		for _, org := range conf.Application.Organizations {
+			org.ID = "OtherOrg"
+		}
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+
+		err := bs.CanRead(identity)
+		denied := &channelconfig.PolicyDeniedError{}
+		require.True(t, errors.As(err, &denied))
+		require.Equal(t, policies.ChannelReaders, denied.PolicyName)
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, (&channelconfig.BundleSource{}).CanRead(identity))
+	})
+}
+
+func TestBundleSourceResolvePolicy(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	for _, path := range []string{
+		"/Channel/Application/SampleOrg/Writers",
+		"Channel/Application/SampleOrg/Writers",
+		"/Application/SampleOrg/Writers",
+		"Application/SampleOrg/Writers/",
+		"//Channel//Application/SampleOrg/Writers",
+	} {
+		t.Run(path, func(t *testing.T) {
+			policy, ok := bs.ResolvePolicy(path)
+			require.True(t, ok)
+			require.NotNil(t, policy)
+		})
+	}
+
+	policy, ok := bs.ResolvePolicy("/Channel/Readers")
+	require.True(t, ok)
+	require.NotNil(t, policy)
+
+	for _, path := range []string{
+		"",
+		"/",
+		"/Channel",
+		"/Channel/Missing",
+		"/Channel/Application/Missing/Writers",
+		"/Channel/Application/SampleOrg/Missing",
+	} {
+		t.Run("Missing"+path, func(t *testing.T) {
+			policy, ok := bs.ResolvePolicy(path)
+			require.False(t, ok)
+			require.Nil(t, policy)
+		})
+	}
+
+	_, ok = (&channelconfig.BundleSource{}).ResolvePolicy("/Channel/Readers")
+	require.False(t, ok)
+}
+
+func TestBundleSourceOrgPolicyManager(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	for _, tc := range []struct {
+		group   string
+		orgName string
+		path    string
+	}{
+		{"Application", "SampleOrg", "/Channel/Application/SampleOrg/Admins"},
+		{"Orderer", "SampleOrg", "/Channel/Orderer/SampleOrg/Admins"},
+		{"Consortiums", "SampleConsortium/SampleOrg", "/Channel/Consortiums/SampleConsortium/SampleOrg/Admins"},
+	} {
+		t.Run(tc.group, func(t *testing.T) {
+			manager, ok := bs.OrgPolicyManager(tc.group, tc.orgName)
+			require.True(t, ok)
+			policy, ok := manager.GetPolicy("Admins")
+			require.True(t, ok)
+			expected, ok := bs.ResolvePolicy(tc.path)
+			require.True(t, ok)
+			require.Equal(t, expected, policy)
+		})
+	}
+
+	for _, tc := range []struct {
+		group   string
+		orgName string
+	}{
+		{"Application", "Missing"},
+		{"Application", ""},
+		{"Orderer", "SampleOrg/Admins"},
+		{"Consortiums", "SampleOrg"},
+		{"Consortiums", "SampleConsortium/"},
+		{"Consortiums", "SampleConsortium/SampleOrg/Extra"},
+		{"Channel", "SampleOrg"},
+	} {
+		t.Run("Missing"+tc.group+"/"+tc.orgName, func(t *testing.T) {
+			manager, ok := bs.OrgPolicyManager(tc.group, tc.orgName)
+			require.False(t, ok)
+			require.Nil(t, manager)
+		})
+	}
+
+	_, ok := (&channelconfig.BundleSource{}).OrgPolicyManager("Application", "SampleOrg")
+	require.False(t, ok)
+}
+
+func TestBundleSourceModPolicies(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+
+	modPolicy, ok := bs.ChannelModPolicy()
+	require.True(t, ok)
+	require.Equal(t, "Admins", modPolicy)
+
+	modPolicy, ok = bs.ModPolicyFor("Application")
+	require.True(t, ok)
+	require.Equal(t, "Admins", modPolicy)
+
+	for _, group := range []string{"Consortiums", "Channel", "Missing"} {
+		modPolicy, ok = bs.ModPolicyFor(group)
+		require.False(t, ok, group)
+		require.Empty(t, modPolicy)
+	}
+
+	bs = channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	_, ok = bs.ChannelModPolicy()
+	require.False(t, ok)
+	_, ok = bs.ModPolicyFor("Application")
+	require.False(t, ok)
+
+	_, ok = (&channelconfig.BundleSource{}).ChannelModPolicy()
+	require.False(t, ok)
+}
+
+func TestBundleSourceWalkPolicies(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+
+	var paths []string
+	err := bs.WalkPolicies(func(path string, policy policies.Policy) error {
+		require.NotNil(t, policy)
+		paths = append(paths, path)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/Channel/Admins",
+		"/Channel/Application/Admins",
+		"/Channel/Application/Endorsement",
+		"/Channel/Application/LifecycleEndorsement",
+		"/Channel/Application/Readers",
+		"/Channel/Application/SampleOrg/Admins",
+		"/Channel/Application/SampleOrg/Endorsement",
+		"/Channel/Application/SampleOrg/Readers",
+		"/Channel/Application/SampleOrg/Writers",
+		"/Channel/Application/Writers",
+		"/Channel/Readers",
+		"/Channel/Writers",
+	}, paths)
+
+	var visited int
+	err = bs.WalkPolicies(func(path string, policy policies.Policy) error {
+		visited++
+		if path == "/Channel/Application/Readers" {
+			return errors.New("stop")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "stop")
+	require.Equal(t, 5, visited)
+
+	err = (&channelconfig.BundleSource{}).WalkPolicies(nil)
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceImplicitMetaRule(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	rule, subPolicy, ok := bs.ImplicitMetaRule("/Channel/Application/Admins")
+	require.True(t, ok)
+	require.Equal(t, "MAJORITY", rule)
+	require.Equal(t, "Admins", subPolicy)
+
+	rule, subPolicy, ok = bs.ImplicitMetaRule("Application/Writers")
+	require.True(t, ok)
+	require.Equal(t, "ANY", rule)
+	require.Equal(t, "Writers", subPolicy)
+
+	for _, path := range []string{
+		"/Channel/Application/SampleOrg/Admins",
+		"/Channel/Application/Missing",
+		"/Channel/Missing/Admins",
+		"",
+	} {
+		rule, subPolicy, ok := bs.ImplicitMetaRule(path)
+		require.False(t, ok, path)
+		require.Empty(t, rule)
+		require.Empty(t, subPolicy)
+	}
+
+	_, _, ok = (&channelconfig.BundleSource{}).ImplicitMetaRule("/Channel/Admins")
+	require.False(t, ok)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	"github.com/hyperledger/fabric/protoutil"
+)
+
+// maxCachedEvaluations bounds the number of results a CachedEvaluator retains
+// for a bundle.  As most signature sets are only ever evaluated a few times,
+// the cache is simply cleared once the bound is reached.
+const maxCachedEvaluations = 10000
+
+// CachedEvaluator evaluates policies like BundleSource.EvaluatePolicy, but
+// memoizes the result of evaluating a policy against a signature set for as
+// long as the bundle it was evaluated with is the current bundle of the
+// BundleSource.  Results are discarded on the first evaluation after an
+// Update or Rollback, so they never outlive the config they were computed
+// with.
+//
+// As the result of an evaluation is reused for the lifetime of the bundle,
+// identities which expire in the meantime remain accepted for the signature
+// sets they were already evaluated with, until the bundle is replaced.  A
+// CachedEvaluator is safe for concurrent use.
+type CachedEvaluator struct {
+	bundleSource *BundleSource
+
+	mutex      sync.Mutex
+	generation *bundleGeneration
+	results    map[cachedEvaluationKey]error
+}
+
+type cachedEvaluationKey struct {
+	policyName   string
+	signatureSet [sha256.Size]byte
+}
+
+// NewCachedEvaluator returns a CachedEvaluator for the policies of this
+// BundleSource.
+func (bs *BundleSource) NewCachedEvaluator() *CachedEvaluator {
+	return &CachedEvaluator{bundleSource: bs}
+}
+
+// Evaluate returns the result of evaluating the named policy against the
+// given signature set with the current bundle, as returned by
+// BundleSource.EvaluatePolicy, reusing a previous result if the same signature
+// set was already evaluated against the policy with the current bundle.
+func (ce *CachedEvaluator) Evaluate(policyName string, signatureSet []*protoutil.SignedData) error {
+	current, err := ce.bundleSource.load()
+	if err != nil {
+		return err
+	}
+	key := cachedEvaluationKey{
+		policyName:   policyName,
+		signatureSet: hashSignatureSet(signatureSet),
+	}
+
+	ce.mutex.Lock()
+	if ce.generation != current {
+		ce.generation = current
+		ce.results = map[cachedEvaluationKey]error{}
+	}
+	result, ok := ce.results[key]
+	ce.mutex.Unlock()
+	if ok {
+		return result
+	}
+
+	result = evaluatePolicy(current.bundle.PolicyManager(), policyName, signatureSet)
+
+	ce.mutex.Lock()
+	if ce.generation == current {
+		if len(ce.results) >= maxCachedEvaluations {
+			ce.results = map[cachedEvaluationKey]error{}
+		}
+		ce.results[key] = result
+	}
+	ce.mutex.Unlock()
+	return result
+}
+
+// hashSignatureSet returns a digest identifying the data, identity, and
+// signature of every element of the signature set, in order.
+func hashSignatureSet(signatureSet []*protoutil.SignedData) [sha256.Size]byte {
+	hash := sha256.New()
+	var length [8]byte
+	write := func(b []byte) {
+		binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+		hash.Write(length[:])
+		hash.Write(b)
+	}
+	for _, signedData := range signatureSet {
+		write(signedData.Data)
+		write(signedData.Identity)
+		write(signedData.Signature)
+	}
+
+	var digest [sha256.Size]byte
+	copy(digest[:], hash.Sum(nil))
+	return digest
+}
@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+// countingPolicy accepts signature sets whose first element carries the data
+// "accept", and counts its evaluations.
+type countingPolicy struct {
+	evaluations int32
+}
+
+func (p *countingPolicy) EvaluateSignedData(signatureSet []*protoutil.SignedData) error {
+	atomic.AddInt32(&p.evaluations, 1)
+	if len(signatureSet) == 0 || string(signatureSet[0].Data) != "accept" {
+		return errors.New("rejected")
+	}
+	return nil
+}
+
+func (p *countingPolicy) EvaluateIdentities([]msp.Identity) error {
+	return errors.New("not implemented")
+}
+
+type singlePolicyManager struct {
+	name   string
+	policy policies.Policy
+}
+
+func (m *singlePolicyManager) GetPolicy(id string) (policies.Policy, bool) {
+	if id != m.name {
+		return nil, false
+	}
+	return m.policy, true
+}
+
+func (m *singlePolicyManager) Manager([]string) (policies.Manager, bool) {
+	return nil, false
+}
+
+func TestCachedEvaluator(t *testing.T) {
+	policy := &countingPolicy{}
+	bs := NewBundleSource(&Bundle{policyManager: &singlePolicyManager{name: "/Channel/Writers", policy: policy}})
+	evaluator := bs.NewCachedEvaluator()
+
+	accepted := []*protoutil.SignedData{{Data: []byte("accept"), Identity: []byte("identity"), Signature: []byte("signature")}}
+	rejected := []*protoutil.SignedData{{Data: []byte("reject"), Identity: []byte("identity"), Signature: []byte("signature")}}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, evaluator.Evaluate("/Channel/Writers", accepted))
+	}
+	require.Equal(t, int32(1), policy.evaluations)
+
+	for i := 0; i < 3; i++ {
+		err := evaluator.Evaluate("/Channel/Writers", rejected)
+		require.EqualError(t, err, "policy /Channel/Writers not satisfied: rejected")
+	}
+	require.Equal(t, int32(2), policy.evaluations)
+
+	// signature sets are distinguished by their elements, not their data alone
+	other := []*protoutil.SignedData{{Data: []byte("accept"), Identity: []byte("identity"), Signature: []byte("other")}}
+	require.NoError(t, evaluator.Evaluate("/Channel/Writers", other))
+	require.Equal(t, int32(3), policy.evaluations)
+
+	err := evaluator.Evaluate("/Channel/Missing", accepted)
+	require.Equal(t, &PolicyNotFoundError{PolicyName: "/Channel/Missing"}, err)
+
+	// an update invalidates all results
+	newPolicy := &countingPolicy{}
+	bs.Update(&Bundle{policyManager: &singlePolicyManager{name: "/Channel/Writers", policy: newPolicy}})
+	require.NoError(t, evaluator.Evaluate("/Channel/Writers", accepted))
+	require.NoError(t, evaluator.Evaluate("/Channel/Writers", accepted))
+	require.Equal(t, int32(1), newPolicy.evaluations)
+	require.Equal(t, int32(3), policy.evaluations)
+
+	bs.Update(&Bundle{policyManager: &singlePolicyManager{name: "/Channel/Readers", policy: newPolicy}})
+	err = evaluator.Evaluate("/Channel/Writers", accepted)
+	require.Equal(t, &PolicyNotFoundError{PolicyName: "/Channel/Writers"}, err)
+
+	err = (&BundleSource{}).NewCachedEvaluator().Evaluate("/Channel/Writers", accepted)
+	require.Equal(t, ErrBundleSourceNotInitialized, err)
+}
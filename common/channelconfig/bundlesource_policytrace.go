@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+)
+
+// PolicyTrace describes the evaluation of a policy against a signature set.
+// Implicit meta policies are traced along with the sub-policies they
+// aggregate, and signature policies along with the signatures satisfying each
+// of their principals.
+type PolicyTrace struct {
+	// Path is the fully qualified path of the policy, such as
+	// /Channel/Application/Writers.
+	Path string
+	// Type is the type of the policy as defined in the config, or UNKNOWN if
+	// the policy is not defined in the config the bundle was built from.
+	Type cb.Policy_PolicyType
+	// Rule is the rule of an implicit meta policy, such as MAJORITY Admins.
+	Rule string
+	// Satisfied reports whether the signature set satisfies the policy, and
+	// Err is the error returned by its evaluation otherwise.
+	Satisfied bool
+	Err       error
+	// SubPolicies are the traces of the sub-policies of an implicit meta
+	// policy, ordered by the name of their group.
+	SubPolicies []*PolicyTrace
+	// Principals are the traces of the principals of a signature policy, in
+	// the order in which the policy defines them.
+	Principals []*PrincipalTrace
+}
+
+// PrincipalTrace describes which signatures of a signature set satisfy a
+// principal of a signature policy.
+type PrincipalTrace struct {
+	Principal *mspprotos.MSPPrincipal
+	// Signatures are the indices of the signed data in the signature set whose
+	// signature is valid and whose identity satisfies the principal.
+	Signatures []int
+}
+
+// EvaluatePolicyTraced behaves like EvaluatePolicy, but additionally returns a
+// trace of the evaluation, unless the policy does not exist.  The trace is
+// built from the config and the policy manager of the same stable bundle by
+// evaluating every sub-policy on its own, and has no bearing on the returned
+// error, which is always the one EvaluatePolicy would return.
+func (bs *BundleSource) EvaluatePolicyTraced(policyName string, signatureSet []*protoutil.SignedData) (*PolicyTrace, error) {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	err = evaluatePolicy(bundle.PolicyManager(), policyName, signatureSet)
+	if _, ok := err.(*PolicyNotFoundError); ok {
+		return nil, err
+	}
+
+	tracer := &policyTracer{
+		bundle:       bundle,
+		signatureSet: signatureSet,
+		identities:   verifiedIdentities(bundle.MSPManager(), signatureSet),
+	}
+	return tracer.trace(policyPathSegments(policyName)), err
+}
+
+type policyTracer struct {
+	bundle       *Bundle
+	signatureSet []*protoutil.SignedData
+	identities   []msp.Identity
+}
+
+// verifiedIdentities returns the identities of the signature set whose
+// signature is valid, at the index of their signed data, and nil for the
+// others.
+func verifiedIdentities(deserializer msp.IdentityDeserializer, signatureSet []*protoutil.SignedData) []msp.Identity {
+	identities := make([]msp.Identity, len(signatureSet))
+	if deserializer == nil {
+		return identities
+	}
+	for i, signedData := range signatureSet {
+		identity, err := deserializer.DeserializeIdentity(signedData.Identity)
+		if err != nil {
+			continue
+		}
+		if err := identity.Verify(signedData.Data, signedData.Signature); err != nil {
+			continue
+		}
+		identities[i] = identity
+	}
+	return identities
+}
+
+func (pt *policyTracer) trace(segments []string) *PolicyTrace {
+	path := policies.PathSeparator + strings.Join(append([]string{RootGroupKey}, segments...), policies.PathSeparator)
+	trace := &PolicyTrace{Path: path}
+
+	policy, ok := resolvePolicy(pt.bundle.PolicyManager(), path)
+	if !ok {
+		trace.Err = &PolicyNotFoundError{PolicyName: path}
+		return trace
+	}
+	trace.Err = policy.EvaluateSignedData(pt.signatureSet)
+	trace.Satisfied = trace.Err == nil
+
+	group, configPolicy := lookupConfigPolicy(pt.bundle.ConfigProto(), segments)
+	if configPolicy.GetPolicy() == nil {
+		return trace
+	}
+	trace.Type = cb.Policy_PolicyType(configPolicy.Policy.Type)
+
+	switch trace.Type {
+	case cb.Policy_IMPLICIT_META:
+		definition := &cb.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, definition); err != nil {
+			return trace
+		}
+		trace.Rule = fmt.Sprintf("%s %s", definition.Rule, definition.SubPolicy)
+
+		groupSegments := segments[:len(segments)-1]
+		childNames := make([]string, 0, len(group.Groups))
+		for childName := range group.Groups {
+			childNames = append(childNames, childName)
+		}
+		sort.Strings(childNames)
+		for _, childName := range childNames {
+			childSegments := append(append(append([]string{}, groupSegments...), childName), definition.SubPolicy)
+			trace.SubPolicies = append(trace.SubPolicies, pt.trace(childSegments))
+		}
+	case cb.Policy_SIGNATURE:
+		envelope := &cb.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, envelope); err != nil {
+			return trace
+		}
+		for _, principal := range envelope.Identities {
+			principalTrace := &PrincipalTrace{Principal: principal}
+			for i, identity := range pt.identities {
+				if identity != nil && identity.SatisfiesPrincipal(principal) == nil {
+					principalTrace.Signatures = append(principalTrace.Signatures, i)
+				}
+			}
+			trace.Principals = append(trace.Principals, principalTrace)
+		}
+	}
+	return trace
+}
+
+// lookupConfigPolicy returns the group and the definition of the policy at the
+// given path segments below the Channel group of the config, or nils if the
+// config does not define it.
+func lookupConfigPolicy(config *cb.Config, segments []string) (*cb.ConfigGroup, *cb.ConfigPolicy) {
+	group := config.GetChannelGroup()
+	if group == nil || len(segments) == 0 {
+		return nil, nil
+	}
+	for _, segment := range segments[:len(segments)-1] {
+		if group = group.Groups[segment]; group == nil {
+			return nil, nil
+		}
+	}
+	configPolicy, ok := group.Policies[segments[len(segments)-1]]
+	if !ok {
+		return nil, nil
+	}
+	return group, configPolicy
+}
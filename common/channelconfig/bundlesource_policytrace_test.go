@@ -0,0 +1,112 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSigner returns the signing identity of the sample MSP, which is an
+// admin of SampleOrg.
+func newTestSigner(t *testing.T) msp.SigningIdentity {
+	mspDir := configtest.GetDevMspDir()
+	conf, err := msp.GetLocalMspConfig(mspDir, nil, "SampleOrg")
+	require.NoError(t, err)
+
+	ks, err := sw.NewFileBasedKeyStore(nil, filepath.Join(mspDir, "keystore"), true)
+	require.NoError(t, err)
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(ks)
+	require.NoError(t, err)
+	localMSP, err := msp.New(&msp.BCCSPNewOpts{NewBaseOpts: msp.NewBaseOpts{Version: msp.MSPv1_0}}, cryptoProvider)
+	require.NoError(t, err)
+	require.NoError(t, localMSP.Setup(conf))
+
+	signer, err := localMSP.GetDefaultSigningIdentity()
+	require.NoError(t, err)
+	return signer
+}
+
+func newTestSignedData(t *testing.T, data []byte) *protoutil.SignedData {
+	signer := newTestSigner(t)
+	identity, err := signer.Serialize()
+	require.NoError(t, err)
+	signature, err := signer.Sign(data)
+	require.NoError(t, err)
+	return &protoutil.SignedData{Data: data, Identity: identity, Signature: signature}
+}
+
+func TestBundleSourceEvaluatePolicyTraced(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	signedData := newTestSignedData(t, []byte("data"))
+	badSignature := &protoutil.SignedData{Data: []byte("other data"), Identity: signedData.Identity, Signature: signedData.Signature}
+
+	t.Run("Satisfied", func(t *testing.T) {
+		signatureSet := []*protoutil.SignedData{badSignature, signedData}
+		trace, err := bs.EvaluatePolicyTraced("/Channel/Application/Writers", signatureSet)
+		require.NoError(t, err)
+		require.Equal(t, bs.EvaluatePolicy("/Channel/Application/Writers", signatureSet), err)
+
+		require.Equal(t, "/Channel/Application/Writers", trace.Path)
+		require.Equal(t, cb.Policy_IMPLICIT_META, trace.Type)
+		require.Equal(t, "ANY Writers", trace.Rule)
+		require.True(t, trace.Satisfied)
+		require.NoError(t, trace.Err)
+		require.Len(t, trace.SubPolicies, 1)
+
+		sub := trace.SubPolicies[0]
+		require.Equal(t, "/Channel/Application/SampleOrg/Writers", sub.Path)
+		require.Equal(t, cb.Policy_SIGNATURE, sub.Type)
+		require.True(t, sub.Satisfied)
+		require.Empty(t, sub.SubPolicies)
+		require.NotEmpty(t, sub.Principals)
+		for _, principal := range sub.Principals {
+			require.NotNil(t, principal.Principal)
+			require.Equal(t, []int{1}, principal.Signatures)
+		}
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		signatureSet := []*protoutil.SignedData{badSignature}
+		trace, err := bs.EvaluatePolicyTraced("Application/Writers", signatureSet)
+		require.Equal(t, bs.EvaluatePolicy("Application/Writers", signatureSet), err)
+		denied := &channelconfig.PolicyDeniedError{}
+		require.True(t, errors.As(err, &denied))
+
+		require.Equal(t, "/Channel/Application/Writers", trace.Path)
+		require.False(t, trace.Satisfied)
+		require.Error(t, trace.Err)
+		require.Len(t, trace.SubPolicies, 1)
+		require.False(t, trace.SubPolicies[0].Satisfied)
+		for _, principal := range trace.SubPolicies[0].Principals {
+			require.Empty(t, principal.Signatures)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		trace, err := bs.EvaluatePolicyTraced("/Channel/Missing", nil)
+		require.EqualError(t, err, "policy /Channel/Missing not found")
+		require.Nil(t, trace)
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		trace, err := (&channelconfig.BundleSource{}).EvaluatePolicyTraced("/Channel/Readers", nil)
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+		require.Nil(t, trace)
+	})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// WithReducedMSP causes the BundleSource to store a reduced copy of every
+// bundle it is updated with, whose MSPs retain only the material required to
+// validate identities and evaluate policies, in order to lower the memory
+// footprint of channels which are only read from.  Currently, the TLS root
+// and intermediate certificates of the Fabric MSPs are discarded.
+//
+// A reduced bundle no longer reflects the channel config: its ConfigProto
+// lacks the discarded material, so it must not be used to validate, author,
+// or compare config updates, and the TLS CAs of the channel orgs cannot be
+// derived from it, as required for cluster communication or TLS pinning.
+// Consequently, the bundles returned by the BundleSource are not the ones
+// passed to Update.  If a bundle cannot be reduced, a warning is logged and
+// the bundle is stored as is.
+func WithReducedMSP() BundleSourceOption {
+	return func(bs *BundleSource) {
+		bs.reducedMSP = true
+	}
+}
+
+// reduceMSPs returns a copy of the bundle without the MSP material discarded
+// by WithReducedMSP, or the bundle itself if there is nothing to discard.
+func reduceMSPs(bundle *Bundle) (*Bundle, error) {
+	if bundle.ConfigProto() == nil {
+		return bundle, nil
+	}
+
+	config := proto.Clone(bundle.ConfigProto()).(*cb.Config)
+	reduced, err := reduceGroupMSPs(config.ChannelGroup)
+	if err != nil {
+		return nil, err
+	}
+	if !reduced {
+		return bundle, nil
+	}
+	return bundle.rebuild(config)
+}
+
+// reduceGroupMSPs discards the TLS certificates of the Fabric MSP definitions
+// in the group and its sub-groups, and returns whether any were discarded.
+func reduceGroupMSPs(group *cb.ConfigGroup) (bool, error) {
+	var reduced bool
+
+	if value, ok := group.Values[MSPKey]; ok {
+		mspConfig := &mspprotos.MSPConfig{}
+		if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+			return false, errors.Wrap(err, "failed to unmarshal MSP config")
+		}
+
+		if mspConfig.Type == int32(msp.FABRIC) {
+			fabricConfig := &mspprotos.FabricMSPConfig{}
+			if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+				return false, errors.Wrap(err, "failed to unmarshal fabric MSP config")
+			}
+
+			if len(fabricConfig.TlsRootCerts) > 0 || len(fabricConfig.TlsIntermediateCerts) > 0 {
+				fabricConfig.TlsRootCerts = nil
+				fabricConfig.TlsIntermediateCerts = nil
+
+				var err error
+				if mspConfig.Config, err = proto.Marshal(fabricConfig); err != nil {
+					return false, errors.Wrap(err, "failed to marshal fabric MSP config")
+				}
+				if value.Value, err = proto.Marshal(mspConfig); err != nil {
+					return false, errors.Wrap(err, "failed to marshal MSP config")
+				}
+				reduced = true
+			}
+		}
+	}
+
+	for _, child := range group.Groups {
+		childReduced, err := reduceGroupMSPs(child)
+		if err != nil {
+			return false, err
+		}
+		reduced = reduced || childReduced
+	}
+	return reduced, nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceReducedMSP(t *testing.T) {
+	full := newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile)
+	fullMSPs, err := full.MSPManager().GetMSPs()
+	require.NoError(t, err)
+	require.NotEmpty(t, fullMSPs["SampleOrg"].GetTLSRootCerts())
+
+	bs := channelconfig.NewBundleSourceWithOptions(full, channelconfig.WithReducedMSP())
+	reduced := bs.StableBundle()
+	require.False(t, reduced == full)
+
+	msps, err := reduced.MSPManager().GetMSPs()
+	require.NoError(t, err)
+	require.Empty(t, msps["SampleOrg"].GetTLSRootCerts())
+	require.Empty(t, msps["SampleOrg"].GetTLSIntermediateCerts())
+
+	diff := full.Diff(reduced)
+	require.Equal(t, &channelconfig.ConfigDiff{Application: true, MSPs: true}, diff)
+
+	cert, err := ioutil.ReadFile(filepath.Join(configtest.GetDevMspDir(), "signcerts", "peer.pem"))
+	require.NoError(t, err)
+	identity, err := bs.ValidateIdentity(protoutil.MarshalOrPanic(&mspprotos.SerializedIdentity{Mspid: "SampleOrg", IdBytes: cert}))
+	require.NoError(t, err)
+	require.Equal(t, "SampleOrg", identity.GetMSPIdentifier())
+
+	_, ok := bs.ResolvePolicy("/Channel/Application/SampleOrg/Admins")
+	require.True(t, ok)
+
+	t.Run("NothingToReduce", func(t *testing.T) {
+		require.NoError(t, bs.WithBundle(func(bundle *channelconfig.Bundle) error {
+			bs.Update(bundle)
+			return nil
+		}))
+		require.True(t, bs.StableBundle() == reduced)
+
+		empty := &channelconfig.Bundle{}
+		bs.Update(empty)
+		require.True(t, bs.StableBundle() == empty)
+	})
+}
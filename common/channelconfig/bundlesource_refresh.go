@@ -0,0 +1,144 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// RefreshMSP replaces the definition of the Fabric MSP with the given ID, in
+// every group of the config of the current bundle in which it is defined,
+// with the given definition, and stores a bundle whose MSP manager is built
+// from the resulting definitions like Update does: the sequence advances and
+// callbacks and listeners, such as those registered via
+// OnRevocationListChange, are invoked.  This allows refreshing MSP material
+// such as revocation lists from an external source without a config
+// transaction.
+//
+// Only the MSP manager of the stored bundle, and the policies resolving
+// principals with it, reflect the refreshed definition.  Its ConfigProto and
+// ConfigtxValidator are still those of the config committed to the channel,
+// so that config updates, fingerprints, and diffs computed from it agree with
+// the ledger, and so is the bundle built by Clone or from the next config
+// update.  The MSP ID itself cannot be changed, as that requires a config
+// update; an error is returned if the definition names a different MSP, if the
+// MSP is not defined in the config, or if the refreshed MSP manager or the
+// bundle cannot be built, in which case the current bundle is retained.
+func (bs *BundleSource) RefreshMSP(mspID string, newConfig *mspprotos.FabricMSPConfig) error {
+	if newConfig == nil {
+		return errors.New("MSP config cannot be nil")
+	}
+	if newConfig.Name != mspID {
+		return errors.Errorf("MSP config is for MSP %s, not %s, and changing the MSP ID requires a config update", newConfig.Name, mspID)
+	}
+	fabricConfig, err := proto.Marshal(newConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal fabric MSP config")
+	}
+
+	if _, err := bs.load(); err != nil {
+		return err
+	}
+
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	current := bs.current().bundle
+	if current.ConfigProto() == nil {
+		return errors.New("current bundle was not built from a config")
+	}
+
+	mspConfig := proto.Clone(current.mspConfigProto()).(*cb.Config)
+	refreshed, err := refreshGroupMSP(mspConfig.ChannelGroup, mspID, fabricConfig)
+	if err != nil {
+		return err
+	}
+	if !refreshed {
+		return errors.Errorf("MSP %s is not defined in the channel config", mspID)
+	}
+
+	bundle, err := current.rebuildWithMSPs(mspConfig)
+	if err != nil {
+		return errors.WithMessagef(err, "could not build bundle with refreshed MSP %s", mspID)
+	}
+	bs.update(bundle)
+	return nil
+}
+
+// rebuildWithMSPs rebuilds the bundle from its own config, but with an MSP
+// manager built from the MSP definitions of the given config.
+func (b *Bundle) rebuildWithMSPs(mspConfig *cb.Config) (*Bundle, error) {
+	channelConfig, err := NewChannelConfig(mspConfig.ChannelGroup, b.bccsp)
+	if err != nil {
+		return nil, err
+	}
+	mspManagerFactory := WithMSPManagerFactory(func(*cb.Config) (msp.MSPManager, error) {
+		return channelConfig.MSPManager(), nil
+	})
+
+	opts := append(append([]BundleOption{}, b.options...), mspManagerFactory)
+	bundle, err := NewBundle(b.channelID(), proto.Clone(b.config).(*cb.Config), b.bccsp, opts...)
+	if err != nil {
+		return nil, err
+	}
+	bundle.mspConfig = mspConfig
+	bundle.options = b.options
+	bundle.configBlockNumber = b.configBlockNumber
+	bundle.configBlockHash = b.configBlockHash
+	return bundle, nil
+}
+
+// mspConfigProto returns the config the MSP manager of the bundle was built
+// from, which is its ConfigProto unless its MSP was refreshed by RefreshMSP.
+func (b *Bundle) mspConfigProto() *cb.Config {
+	if b.mspConfig != nil {
+		return b.mspConfig
+	}
+	return b.ConfigProto()
+}
+
+// refreshGroupMSP replaces the fabric MSP config of the MSP definitions with
+// the given MSP ID in the group and its sub-groups, and returns whether any
+// were found.
+func refreshGroupMSP(group *cb.ConfigGroup, mspID string, fabricConfig []byte) (bool, error) {
+	var refreshed bool
+
+	if value, ok := group.Values[MSPKey]; ok {
+		mspConfig := &mspprotos.MSPConfig{}
+		if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+			return false, errors.Wrap(err, "failed to unmarshal MSP config")
+		}
+		id, err := mspIDFromConfig(mspConfig)
+		if err != nil {
+			return false, err
+		}
+
+		if id == mspID {
+			if mspConfig.Type != int32(msp.FABRIC) {
+				return false, errors.Errorf("MSP %s is not a fabric MSP", mspID)
+			}
+			mspConfig.Config = fabricConfig
+			if value.Value, err = proto.Marshal(mspConfig); err != nil {
+				return false, errors.Wrap(err, "failed to marshal MSP config")
+			}
+			refreshed = true
+		}
+	}
+
+	for _, child := range group.Groups {
+		childRefreshed, err := refreshGroupMSP(child, mspID, fabricConfig)
+		if err != nil {
+			return false, err
+		}
+		refreshed = refreshed || childRefreshed
+	}
+	return refreshed, nil
+}
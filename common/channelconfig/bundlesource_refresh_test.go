@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+// fabricMSPConfigs returns the fabric MSP configs of all MSP definitions in
+// the group and its sub-groups.
+func fabricMSPConfigs(t *testing.T, group *cb.ConfigGroup) []*mspprotos.FabricMSPConfig {
+	var result []*mspprotos.FabricMSPConfig
+	if value, ok := group.Values[channelconfig.MSPKey]; ok {
+		mspConfig := &mspprotos.MSPConfig{}
+		require.NoError(t, proto.Unmarshal(value.Value, mspConfig))
+		fabricConfig := &mspprotos.FabricMSPConfig{}
+		require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricConfig))
+		result = append(result, fabricConfig)
+	}
+	for _, child := range group.Groups {
+		result = append(result, fabricMSPConfigs(t, child)...)
+	}
+	return result
+}
+
+func TestBundleSourceRefreshMSP(t *testing.T) {
+	crl, err := ioutil.ReadFile(filepath.Join("..", "..", "msp", "testdata", "revocation", "crls", "crl.pem"))
+	require.NoError(t, err)
+
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(bundle)
+
+	var revocations, removedCRLs [][]byte
+	bs.OnRevocationListChange(func(mspID string, added, removed [][]byte) {
+		require.Equal(t, "SampleOrg", mspID)
+		revocations = append(revocations, added...)
+		removedCRLs = append(removedCRLs, removed...)
+	})
+
+	mspConfigs := fabricMSPConfigs(t, bundle.ConfigProto().ChannelGroup)
+	require.Len(t, mspConfigs, 3)
+	newConfig := proto.Clone(mspConfigs[0]).(*mspprotos.FabricMSPConfig)
+	newConfig.RevocationList = [][]byte{crl}
+	newConfig.TlsRootCerts = nil
+	newConfig.TlsIntermediateCerts = nil
+
+	require.NoError(t, bs.RefreshMSP("SampleOrg", newConfig))
+	refreshed, sequence := bs.SequencedBundle()
+	require.Equal(t, uint64(2), sequence)
+	require.Equal(t, [][]byte{crl}, revocations)
+	require.Empty(t, removedCRLs)
+
+	// the MSP manager is refreshed, while the committed config is retained
+	msps, err := bundle.MSPManager().GetMSPs()
+	require.NoError(t, err)
+	require.NotEmpty(t, msps["SampleOrg"].GetTLSRootCerts())
+	msps, err = refreshed.MSPManager().GetMSPs()
+	require.NoError(t, err)
+	require.Empty(t, msps["SampleOrg"].GetTLSRootCerts())
+	require.True(t, proto.Equal(bundle.ConfigProto(), refreshed.ConfigProto()))
+	require.True(t, bundle.Equals(refreshed))
+	require.Equal(t, bundle.Fingerprint(), refreshed.Fingerprint())
+	require.Equal(t, bundle.ConfigtxValidator().Sequence(), refreshed.ConfigtxValidator().Sequence())
+	require.Equal(t, bundle.ConfigtxValidator().ChannelID(), refreshed.ConfigtxValidator().ChannelID())
+	require.True(t, proto.Equal(bundle.ConfigtxValidator().ConfigProto(), refreshed.ConfigtxValidator().ConfigProto()))
+
+	// the refresh is dropped by the next bundle built from a config
+	clone, err := refreshed.Clone()
+	require.NoError(t, err)
+	bs.Update(clone)
+	require.Equal(t, [][]byte{crl}, removedCRLs)
+	require.NoError(t, bs.RefreshMSP("SampleOrg", newConfig))
+	refreshed = bs.StableBundle()
+
+	t.Run("ChangedMSPID", func(t *testing.T) {
+		otherConfig := proto.Clone(newConfig).(*mspprotos.FabricMSPConfig)
+		otherConfig.Name = "OtherOrg"
+		err := bs.RefreshMSP("SampleOrg", otherConfig)
+		require.EqualError(t, err, "MSP config is for MSP OtherOrg, not SampleOrg, and changing the MSP ID requires a config update")
+		require.True(t, bs.StableBundle() == refreshed)
+	})
+
+	t.Run("UnknownMSP", func(t *testing.T) {
+		otherConfig := proto.Clone(newConfig).(*mspprotos.FabricMSPConfig)
+		otherConfig.Name = "OtherOrg"
+		err := bs.RefreshMSP("OtherOrg", otherConfig)
+		require.EqualError(t, err, "MSP OtherOrg is not defined in the channel config")
+		require.True(t, bs.StableBundle() == refreshed)
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		badConfig := proto.Clone(newConfig).(*mspprotos.FabricMSPConfig)
+		badConfig.RootCerts = nil
+		err := bs.RefreshMSP("SampleOrg", badConfig)
+		require.Error(t, err)
+		require.Regexp(t, "^could not build bundle with refreshed MSP SampleOrg", err.Error())
+		require.True(t, bs.StableBundle() == refreshed)
+	})
+
+	t.Run("NilConfig", func(t *testing.T) {
+		require.EqualError(t, bs.RefreshMSP("SampleOrg", nil), "MSP config cannot be nil")
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		err := (&channelconfig.BundleSource{}).RefreshMSP("SampleOrg", newConfig)
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+	})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// OnRevocationListChange registers a function which is invoked on Update for
+// every MSP whose revocation list differs between the previous and the new
+// bundle, with the CRLs the new bundle adds to and removes from the revocation
+// list of that MSP.  MSPs are matched by MSP ID, and CRLs are compared by
+// their encoding, so reordering the revocation list is not a change.  An MSP
+// which is only defined by one of the bundles, or which is not a Fabric MSP,
+// is treated as having an empty revocation list.  The function is invoked in
+// the order of the MSP IDs.  The same restrictions as for update listeners
+// apply.
+func (bs *BundleSource) OnRevocationListChange(fn func(mspID string, added, removed [][]byte)) {
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if oldBundle == nil {
+			return
+		}
+
+		oldCRLs, err := revocationLists(oldBundle)
+		if err != nil {
+			logger.Warningf("Could not inspect the revocation lists of previous bundle: %s", err)
+			return
+		}
+		newCRLs, err := revocationLists(newBundle)
+		if err != nil {
+			logger.Warningf("Could not inspect the revocation lists of new bundle: %s", err)
+			return
+		}
+
+		mspIDs := map[string]struct{}{}
+		for mspID := range oldCRLs {
+			mspIDs[mspID] = struct{}{}
+		}
+		for mspID := range newCRLs {
+			mspIDs[mspID] = struct{}{}
+		}
+		sortedIDs := make([]string, 0, len(mspIDs))
+		for mspID := range mspIDs {
+			sortedIDs = append(sortedIDs, mspID)
+		}
+		sort.Strings(sortedIDs)
+
+		for _, mspID := range sortedIDs {
+			added := crlDifference(newCRLs[mspID], oldCRLs[mspID])
+			removed := crlDifference(oldCRLs[mspID], newCRLs[mspID])
+			if len(added) > 0 || len(removed) > 0 {
+				fn(mspID, added, removed)
+			}
+		}
+	})
+}
+
+// revocationLists returns the revocation lists of the Fabric MSPs the MSP
+// manager of the bundle was built from, keyed by MSP ID.
+func revocationLists(bundle *Bundle) (map[string][][]byte, error) {
+	mspConfigs, err := collectMSPConfigs(bundle.mspConfigProto().GetChannelGroup())
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string][][]byte{}
+	for mspID, mspConfig := range mspConfigs {
+		if mspConfig.Type != int32(msp.FABRIC) {
+			continue
+		}
+		fabricConfig := &mspprotos.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal fabric MSP config of %s", mspID)
+		}
+		result[mspID] = fabricConfig.RevocationList
+	}
+	return result, nil
+}
+
+// crlDifference returns the CRLs of a which are not in b, in the order of a.
+func crlDifference(a, b [][]byte) [][]byte {
+	inB := make(map[string]bool, len(b))
+	for _, crl := range b {
+		inB[string(crl)] = true
+	}
+
+	var result [][]byte
+	for _, crl := range a {
+		if !inB[string(crl)] {
+			result = append(result, crl)
+			inB[string(crl)] = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBundleWithRevocationList returns a bundle for the given profile whose
+// MSP definitions carry the given revocation list.
+func newTestBundleWithRevocationList(t *testing.T, profile string, crls ...[]byte) *channelconfig.Bundle {
+	config := proto.Clone(newTestBundle(t, profile).ConfigProto()).(*cb.Config)
+
+	var walk func(group *cb.ConfigGroup)
+	walk = func(group *cb.ConfigGroup) {
+		if value, ok := group.Values[channelconfig.MSPKey]; ok {
+			mspConfig := &mspprotos.MSPConfig{}
+			require.NoError(t, proto.Unmarshal(value.Value, mspConfig))
+			fabricConfig := &mspprotos.FabricMSPConfig{}
+			require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricConfig))
+			fabricConfig.RevocationList = crls
+			mspConfig.Config = protoutil.MarshalOrPanic(fabricConfig)
+			value.Value = protoutil.MarshalOrPanic(mspConfig)
+		}
+		for _, child := range group.Groups {
+			walk(child)
+		}
+	}
+	walk(config.ChannelGroup)
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	bundle, err := channelconfig.NewBundle("testchannel", config, cryptoProvider)
+	require.NoError(t, err)
+	return bundle
+}
+
+func TestBundleSourceOnRevocationListChange(t *testing.T) {
+	profile := genesisconfig.SampleSingleMSPChannelProfile
+
+	crl1, err := ioutil.ReadFile(filepath.Join("..", "..", "msp", "testdata", "revocation", "crls", "crl.pem"))
+	require.NoError(t, err)
+	crl2, err := ioutil.ReadFile(filepath.Join("..", "..", "msp", "testdata", "revocation2", "crls", "crl.pem"))
+	require.NoError(t, err)
+
+	type change struct {
+		mspID          string
+		added, removed [][]byte
+	}
+	var changes []change
+
+	bs := channelconfig.NewBundleSource(newTestBundle(t, profile))
+	bs.OnRevocationListChange(func(mspID string, added, removed [][]byte) {
+		changes = append(changes, change{mspID: mspID, added: added, removed: removed})
+	})
+
+	bs.Update(newTestBundle(t, profile))
+	require.Empty(t, changes)
+
+	bs.Update(newTestBundleWithRevocationList(t, profile, crl1))
+	require.Equal(t, []change{{mspID: "SampleOrg", added: [][]byte{crl1}}}, changes)
+
+	changes = nil
+	bs.Update(newTestBundleWithRevocationList(t, profile, crl1, crl2))
+	require.Equal(t, []change{{mspID: "SampleOrg", added: [][]byte{crl2}}}, changes)
+
+	// reordering the revocation list is not a change
+	changes = nil
+	bs.Update(newTestBundleWithRevocationList(t, profile, crl2, crl1))
+	require.Empty(t, changes)
+
+	bs.Update(newTestBundle(t, profile))
+	require.Equal(t, []change{{mspID: "SampleOrg", removed: [][]byte{crl2, crl1}}}, changes)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sort"
+
+	"github.com/hyperledger/fabric/common/capabilities"
+	"github.com/pkg/errors"
+)
+
+// SelfCheck runs checks on a single stable bundle which, except for the
+// capability check, a plain NewBundle does not run by default, and returns the
+// first violation found, or nil if the bundle is healthy, so that it may back a
+// liveness probe.  In order, it checks that the MSP references resolve, as
+// ValidateMSPReferences does, that the MSP of the local org set via
+// WithLocalMSPID, if any, is defined, that the policy references resolve, as
+// ValidatePolicyReferences does, and that the capabilities of the Channel,
+// Orderer, and Application sections are supported by this binary, in which
+// case an *UnsupportedCapabilityError is returned.
+func (bs *BundleSource) SelfCheck() error {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return err
+	}
+
+	if err := bundle.ValidateMSPReferences(); err != nil {
+		return err
+	}
+	if bs.localMSPID != "" {
+		msps, err := bundleMSPs(bundle)
+		if err != nil {
+			return err
+		}
+		if _, ok := msps[bs.localMSPID]; !ok {
+			return errors.Errorf("local MSP %s is not defined in the channel config", bs.localMSPID)
+		}
+	}
+	if err := bundle.ValidatePolicyReferences(); err != nil {
+		return err
+	}
+	return bundle.capabilitiesSupported()
+}
+
+// capabilitiesSupported checks that the capabilities of all sections of the
+// bundle are supported.
+func (b *Bundle) capabilitiesSupported() error {
+	var supported []func() error
+	if capabilities, ok := b.ChannelCapabilities(); ok {
+		supported = append(supported, capabilities.Supported)
+	}
+	if capabilities, ok := b.OrdererCapabilities(); ok {
+		supported = append(supported, capabilities.Supported)
+	}
+	if capabilities, ok := b.ApplicationCapabilities(); ok {
+		supported = append(supported, capabilities.Supported)
+	}
+	for _, check := range supported {
+		if err := check(); err != nil {
+			return &UnsupportedCapabilityError{Err: err}
+		}
+	}
+	return nil
+}
+
+// UnsupportedCapabilities returns the sorted, distinct names of the
+// capabilities which the Channel, Orderer, or Application section of a single
+// stable bundle enables, but which this binary does not support, so that
+// operators can upgrade before the channel can no longer be processed.  It
+// returns nil if all capabilities are supported or no bundle has been stored.
+func (bs *BundleSource) UnsupportedCapabilities() []string {
+	bundle, err := bs.LoadBundle()
+	if err != nil {
+		return nil
+	}
+
+	supported := map[string]func(capability string) bool{
+		ChannelGroupKey:     capabilities.NewChannelProvider(nil).HasCapability,
+		OrdererGroupKey:     capabilities.NewOrdererProvider(nil).HasCapability,
+		ApplicationGroupKey: capabilities.NewApplicationProvider(nil).HasCapability,
+	}
+	unsupported := map[string]struct{}{}
+	for _, section := range capabilitySections(bundle) {
+		for capability := range section.capabilities.GetCapabilities() {
+			if !supported[section.name](capability) {
+				unsupported[capability] = struct{}{}
+			}
+		}
+	}
+
+	var result []string
+	for capability := range unsupported {
+		result = append(result, capability)
+	}
+	sort.Strings(result)
+	return result
+}
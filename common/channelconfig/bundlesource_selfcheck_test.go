@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceSelfCheck(t *testing.T) {
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+
+	t.Run("Healthy", func(t *testing.T) {
+		require.NoError(t, channelconfig.NewBundleSource(bundle).SelfCheck())
+
+		bs := channelconfig.NewBundleSourceWithOptions(bundle, channelconfig.WithLocalMSPID("SampleOrg"))
+		require.NoError(t, bs.SelfCheck())
+	})
+
+	t.Run("MissingLocalMSP", func(t *testing.T) {
+		bs := channelconfig.NewBundleSourceWithOptions(bundle, channelconfig.WithLocalMSPID("OtherOrg"))
+		require.EqualError(t, bs.SelfCheck(), "local MSP OtherOrg is not defined in the channel config")
+	})
+
+	t.Run("UnsupportedCapability", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		conf.Application.Capabilities["V9_9"] = true
+		bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+
+		err := bs.SelfCheck()
+		var unsupported *channelconfig.UnsupportedCapabilityError
+		require.True(t, errors.As(err, &unsupported))
+		require.EqualError(t, err, "Application capability V9_9 is required but not supported")
+	})
+
+	t.Run("NoMSPManager", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+		require.EqualError(t, bs.SelfCheck(), "bundle has no MSP manager")
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, (&channelconfig.BundleSource{}).SelfCheck())
+	})
+}
+
+func TestBundleSourceUnsupportedCapabilities(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.Nil(t, bs.UnsupportedCapabilities())
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Capabilities["V9_9"] = true
+	conf.Orderer.Capabilities["V9_9"] = true
+	conf.Orderer.Capabilities["V8_0"] = true
+	conf.Application.Capabilities["V2_0"] = true
+	conf.Application.Capabilities["V7_0"] = true
+	bs.Update(newTestBundleFromProfile(t, conf))
+	require.Equal(t, []string{"V7_0", "V8_0", "V9_9"}, bs.UnsupportedCapabilities())
+
+	require.Nil(t, (&channelconfig.BundleSource{}).UnsupportedCapabilities())
+}
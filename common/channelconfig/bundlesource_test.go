@@ -0,0 +1,760 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBundle(t *testing.T, profile string) *channelconfig.Bundle {
+	return newTestBundleFromProfile(t, genesisconfig.Load(profile, configtest.GetDevConfigDir()))
+}
+
+func newTestBundleFromProfile(t *testing.T, conf *genesisconfig.Profile) *channelconfig.Bundle {
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	bundle, err := channelconfig.NewBundle("testchannel", &cb.Config{ChannelGroup: cg}, cryptoProvider)
+	require.NoError(t, err)
+	return bundle
+}
+
+// newTestRaftBundle returns a bundle for an etcdraft system channel whose
+// consenters use the TLS certificates from testdata.
+func newTestRaftBundle(t *testing.T) *channelconfig.Bundle {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeEtcdRaftProfile, configtest.GetDevConfigDir())
+	for i, consenter := range conf.Orderer.EtcdRaft.Consenters {
+		consenter.ClientTlsCert = []byte(fmt.Sprintf("testdata/tls-client-%d.pem", i+1))
+		consenter.ServerTlsCert = []byte(fmt.Sprintf("testdata/tls-server-%d.pem", i+1))
+	}
+	return newTestBundleFromProfile(t, conf)
+}
+
+func TestBundleSourceUpdateListeners(t *testing.T) {
+	initial := &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(initial)
+
+	type transition struct {
+		oldBundle, newBundle, stable *channelconfig.Bundle
+	}
+	var transitions []transition
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+		transitions = append(transitions, transition{
+			oldBundle: oldBundle,
+			newBundle: newBundle,
+			stable:    bs.StableBundle(),
+		})
+	})
+
+	next := &channelconfig.Bundle{}
+	bs.Update(next)
+	require.Len(t, transitions, 1)
+	require.True(t, transitions[0].oldBundle == initial)
+	require.True(t, transitions[0].newBundle == next)
+	require.True(t, transitions[0].stable == next)
+
+	t.Run("PanicPropagates", func(t *testing.T) {
+		bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+			panic("listener failure")
+		})
+		last := &channelconfig.Bundle{}
+		require.PanicsWithValue(t, "listener failure", func() { bs.Update(last) })
+		require.True(t, bs.StableBundle() == last)
+
+		// the source must remain usable after the panic
+		bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {})
+	})
+}
+
+func TestBundleSourceOrdererMSPs(t *testing.T) {
+	t.Run("SystemChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+		msps, ok, err := bs.OrdererMSPs()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, msps, 1)
+		require.Contains(t, msps, "SampleOrg")
+	})
+
+	t.Run("ApplicationChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+		msps, ok, err := bs.OrdererMSPs()
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Nil(t, msps)
+	})
+}
+
+func TestBundleSourceMSPsBySection(t *testing.T) {
+	t.Run("SystemChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+		orderer, application, consortiums, err := bs.MSPsBySection()
+		require.NoError(t, err)
+		require.Len(t, orderer, 1)
+		require.Contains(t, orderer, "SampleOrg")
+		require.Len(t, application, 1)
+		require.Contains(t, application, "SampleOrg")
+		require.Len(t, consortiums, 1)
+		require.Contains(t, consortiums, "SampleOrg")
+
+		msps, err := bs.AllMSPs()
+		require.NoError(t, err)
+		require.Len(t, msps, 1)
+		require.True(t, msps["SampleOrg"] == orderer["SampleOrg"])
+	})
+
+	t.Run("ApplicationChannel", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+		orderer, application, consortiums, err := bs.MSPsBySection()
+		require.NoError(t, err)
+		require.Nil(t, orderer)
+		require.Len(t, application, 1)
+		require.Nil(t, consortiums)
+
+		msps, err := bs.AllMSPs()
+		require.NoError(t, err)
+		require.Equal(t, application, msps)
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		_, err := (&channelconfig.BundleSource{}).AllMSPs()
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+	})
+}
+
+func TestBundleSourceSortedMSPIDs(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	for _, mspID := range []string{"ZetaOrg", "AlphaOrg", "MuOrg"} {
+		org := *conf.Application.Organizations[0]
+		org.Name, org.ID = mspID, mspID
+		conf.Application.Organizations = append(conf.Application.Organizations, &org)
+	}
+
+	bs := channelconfig.NewBundleSource(newTestBundleFromProfile(t, conf))
+	for i := 0; i < 10; i++ {
+		require.Equal(t, []string{"AlphaOrg", "MuOrg", "SampleOrg", "ZetaOrg"}, bs.SortedMSPIDs())
+	}
+
+	require.Nil(t, channelconfig.NewBundleSource(&channelconfig.Bundle{}).SortedMSPIDs())
+	require.Nil(t, (&channelconfig.BundleSource{}).SortedMSPIDs())
+}
+
+func TestBundleSourceExportMSPConfigs(t *testing.T) {
+	caCert, err := ioutil.ReadFile(filepath.Join(configtest.GetDevMspDir(), "cacerts", "cacert.pem"))
+	require.NoError(t, err)
+
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	for _, group := range []string{channelconfig.OrdererGroupKey, channelconfig.ApplicationGroupKey, channelconfig.ConsortiumsGroupKey} {
+		t.Run(group, func(t *testing.T) {
+			mspConfigs, err := bs.ExportMSPConfigs(group)
+			require.NoError(t, err)
+			require.Len(t, mspConfigs, 1)
+			require.Equal(t, "SampleOrg", mspConfigs["SampleOrg"].Name)
+			require.Equal(t, [][]byte{caCert}, mspConfigs["SampleOrg"].RootCerts)
+			require.Nil(t, mspConfigs["SampleOrg"].SigningIdentity)
+		})
+	}
+
+	_, err = bs.ExportMSPConfigs(channelconfig.ChannelGroupKey)
+	require.EqualError(t, err, "group Channel does not contain orgs")
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	_, err = bs.ExportMSPConfigs(channelconfig.OrdererGroupKey)
+	require.EqualError(t, err, "channel config has no Orderer group")
+
+	_, err = (&channelconfig.BundleSource{}).ExportMSPConfigs(channelconfig.ApplicationGroupKey)
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceOrdererTLSCACerts(t *testing.T) {
+	tlsRoot, err := ioutil.ReadFile(filepath.Join(configtest.GetDevMspDir(), "tlscacerts", "tlsroot.pem"))
+	require.NoError(t, err)
+	tlsIntermediate, err := ioutil.ReadFile(filepath.Join(configtest.GetDevMspDir(), "tlsintermediatecerts", "tlsintermediate.pem"))
+	require.NoError(t, err)
+
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	roots, intermediates, err := bs.OrdererTLSCACerts()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{tlsRoot}, roots)
+	require.Equal(t, [][]byte{tlsIntermediate}, intermediates)
+
+	mspConfig := fabricMSPConfigs(t, bs.StableBundle().ConfigProto().ChannelGroup)[0]
+	mspConfig.TlsRootCerts = [][]byte{tlsRoot, tlsRoot}
+	require.NoError(t, bs.RefreshMSP(mspConfig.Name, mspConfig))
+	roots, intermediates, err = bs.OrdererTLSCACerts()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{tlsRoot}, roots)
+	require.Equal(t, [][]byte{tlsIntermediate}, intermediates)
+
+	bs.Update(newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile))
+	_, _, err = bs.OrdererTLSCACerts()
+	require.EqualError(t, err, "channel config has no Orderer group")
+
+	_, _, err = (&channelconfig.BundleSource{}).OrdererTLSCACerts()
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceValidated(t *testing.T) {
+	initial := &channelconfig.Bundle{}
+	reject := func(bundle *channelconfig.Bundle) error { return errors.New("rejected") }
+	accept := func(bundle *channelconfig.Bundle) error { return nil }
+
+	bs, err := channelconfig.NewValidatedBundleSource(initial, accept, reject)
+	require.EqualError(t, err, "bundle validation failed: rejected")
+	require.Nil(t, bs)
+
+	bs, err = channelconfig.NewValidatedBundleSource(initial, accept)
+	require.NoError(t, err)
+	require.True(t, bs.StableBundle() == initial)
+
+	err = bs.UpdateValidated(&channelconfig.Bundle{}, reject)
+	require.EqualError(t, err, "bundle validation failed: rejected")
+	require.True(t, bs.StableBundle() == initial)
+
+	next := &channelconfig.Bundle{}
+	require.NoError(t, bs.UpdateValidated(next, accept))
+	require.True(t, bs.StableBundle() == next)
+}
+
+func TestBundleSourceValidateIdentity(t *testing.T) {
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+
+	cert, err := ioutil.ReadFile(filepath.Join(configtest.GetDevMspDir(), "signcerts", "peer.pem"))
+	require.NoError(t, err)
+	serializedIdentity := protoutil.MarshalOrPanic(&mspprotos.SerializedIdentity{Mspid: "SampleOrg", IdBytes: cert})
+
+	identity, err := bs.ValidateIdentity(serializedIdentity)
+	require.NoError(t, err)
+	require.Equal(t, "SampleOrg", identity.GetMSPIdentifier())
+
+	_, err = bs.ValidateIdentity(protoutil.MarshalOrPanic(&mspprotos.SerializedIdentity{Mspid: "OtherOrg", IdBytes: cert}))
+	require.EqualError(t, err, "could not deserialize identity: MSP OtherOrg is not defined on channel")
+
+	identity, err = bs.ValidateIdentityAtUpdate(serializedIdentity, 1)
+	require.NoError(t, err)
+	require.NotNil(t, identity)
+
+	_, err = bs.ValidateIdentityAtUpdate(serializedIdentity, 0)
+	require.EqualError(t, err, "bundle source sequence is 1, expected 0")
+
+	bs.Update(bs.StableBundle())
+	_, err = bs.ValidateIdentityAtUpdate(serializedIdentity, 1)
+	require.EqualError(t, err, "bundle source sequence is 2, expected 1")
+
+	bs.Update(&channelconfig.Bundle{})
+	_, err = bs.ValidateIdentityAtUpdate(serializedIdentity, 3)
+	require.EqualError(t, err, "bundle has no MSP manager")
+}
+
+func TestBundleSourceSequence(t *testing.T) {
+	initial := &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(initial)
+	require.Equal(t, uint64(1), bs.Sequence())
+
+	var sequences []uint64
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+		sequences = append(sequences, bs.Sequence())
+	})
+
+	next := &channelconfig.Bundle{}
+	bs.Update(next)
+	bs.Update(next)
+	require.Equal(t, []uint64{2, 3}, sequences)
+
+	bundle, seq := bs.SequencedBundle()
+	require.True(t, bundle == next)
+	require.Equal(t, uint64(3), seq)
+}
+
+func TestBundleSourceWithBundle(t *testing.T) {
+	initial := &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(initial)
+
+	next := &channelconfig.Bundle{}
+	err := bs.WithBundle(func(bundle *channelconfig.Bundle) error {
+		bs.Update(next)
+		require.True(t, bundle == initial)
+		return errors.New("fn-error")
+	})
+	require.EqualError(t, err, "fn-error")
+
+	require.NoError(t, bs.WithBundle(func(bundle *channelconfig.Bundle) error {
+		require.True(t, bundle == next)
+		return nil
+	}))
+
+	err = (&channelconfig.BundleSource{}).WithBundle(func(bundle *channelconfig.Bundle) error {
+		t.Fatal("fn must not be invoked")
+		return nil
+	})
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceUpdateFromConfig(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.BatchTimeout = 5 * time.Second
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	config := &cb.Config{ChannelGroup: cg}
+
+	bs := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+	require.NoError(t, bs.UpdateFromConfig(config))
+	require.Equal(t, uint64(2), bs.Sequence())
+	require.Equal(t, "testchannel", bs.ConfigtxValidator().ChannelID())
+	batchTimeout, _ := bs.BatchTimeout()
+	require.Equal(t, 5*time.Second, batchTimeout)
+
+	current := bs.StableBundle()
+	err = bs.UpdateFromConfig(&cb.Config{})
+	require.EqualError(t, err, "config must contain a channel group")
+	require.True(t, bs.StableBundle() == current)
+	require.Equal(t, uint64(2), bs.Sequence())
+
+	t.Run("WithChannelContext", func(t *testing.T) {
+		cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+		require.NoError(t, err)
+		bs := channelconfig.NewBundleSourceWithOptions(&channelconfig.Bundle{}, channelconfig.WithChannelContext("otherchannel", cryptoProvider))
+		require.NoError(t, bs.UpdateFromConfig(config))
+		require.Equal(t, "otherchannel", bs.ConfigtxValidator().ChannelID())
+	})
+
+	t.Run("NoChannelContext", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+		err := bs.UpdateFromConfig(config)
+		require.EqualError(t, err, "current bundle was not built from a config and no channel context is set")
+
+		err = (&channelconfig.BundleSource{}).UpdateFromConfig(config)
+		require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+	})
+}
+
+func TestBundleSourceToken(t *testing.T) {
+	require.False(t, (&channelconfig.BundleSource{}).TokenValid((&channelconfig.BundleSource{}).Token()))
+
+	bs := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	token := bs.Token()
+	require.True(t, bs.TokenValid(token))
+	require.Equal(t, token, bs.Token())
+	require.False(t, bs.TokenValid(channelconfig.BundleToken{}))
+
+	next := &channelconfig.Bundle{}
+	bs.Update(next)
+	require.False(t, bs.TokenValid(token))
+	require.NotEqual(t, token, bs.Token())
+
+	token = bs.Token()
+	bs.Update(next)
+	require.False(t, bs.TokenValid(token))
+
+	other := channelconfig.NewBundleSource(next)
+	require.False(t, other.TokenValid(bs.Token()))
+}
+
+func TestBundleSourceWaitForUpdate(t *testing.T) {
+	initial := &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(initial)
+
+	t.Run("AlreadyReached", func(t *testing.T) {
+		bundle, err := bs.WaitForUpdate(context.Background(), 0)
+		require.NoError(t, err)
+		require.True(t, bundle == initial)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		bundle, err := bs.WaitForUpdate(ctx, 1)
+		require.Equal(t, context.DeadlineExceeded, err)
+		require.Nil(t, bundle)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		next := &channelconfig.Bundle{}
+		result := make(chan *channelconfig.Bundle)
+		go func() {
+			bundle, _ := bs.WaitForUpdate(context.Background(), 1)
+			result <- bundle
+		}()
+
+		bs.Update(next)
+		require.True(t, <-result == next)
+	})
+}
+
+func TestBundleSourceNotInitialized(t *testing.T) {
+	bs := &channelconfig.BundleSource{}
+
+	require.PanicsWithValue(t, channelconfig.ErrBundleSourceNotInitialized, func() { bs.StableBundle() })
+	require.PanicsWithValue(t, channelconfig.ErrBundleSourceNotInitialized, func() { bs.MSPManager() })
+	require.PanicsWithValue(t, channelconfig.ErrBundleSourceNotInitialized, func() { bs.SequencedBundle() })
+
+	bundle, err := bs.LoadBundle()
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+	require.Nil(t, bundle)
+
+	_, ok := bs.OrdererConfig()
+	require.False(t, ok)
+	_, ok = bs.ApplicationConfig()
+	require.False(t, ok)
+	_, ok = bs.ConsortiumsConfig()
+	require.False(t, ok)
+	_, ok = bs.ApplicationCapabilities()
+	require.False(t, ok)
+	require.Equal(t, uint64(0), bs.Sequence())
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, bs.ValidateNew(nil))
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, bs.EvaluatePolicy("/Channel/Readers", nil))
+
+	_, err = bs.WaitForUpdate(context.Background(), 0)
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+
+	initial := &channelconfig.Bundle{}
+	bs.Update(initial)
+	require.True(t, bs.StableBundle() == initial)
+	require.Equal(t, uint64(1), bs.Sequence())
+}
+
+func TestBundleSourceRollback(t *testing.T) {
+	initial := &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(initial)
+
+	bundle, err := bs.Rollback()
+	require.Equal(t, channelconfig.ErrNoPreviousBundle, err)
+	require.Nil(t, bundle)
+	require.True(t, bs.StableBundle() == initial)
+	require.Equal(t, uint64(1), bs.Sequence())
+
+	type transition struct {
+		oldBundle, newBundle *channelconfig.Bundle
+	}
+	var transitions []transition
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+		transitions = append(transitions, transition{oldBundle: oldBundle, newBundle: newBundle})
+	})
+
+	broken := &channelconfig.Bundle{}
+	bs.Update(broken)
+
+	bundle, err = bs.Rollback()
+	require.NoError(t, err)
+	require.True(t, bundle == initial)
+	require.True(t, bs.StableBundle() == initial)
+	require.Equal(t, uint64(3), bs.Sequence())
+	require.Len(t, transitions, 2)
+	require.True(t, transitions[1].oldBundle == broken)
+	require.True(t, transitions[1].newBundle == initial)
+
+	bundle, err = bs.Rollback()
+	require.NoError(t, err)
+	require.True(t, bundle == broken)
+
+	_, err = (&channelconfig.BundleSource{}).Rollback()
+	require.Equal(t, channelconfig.ErrBundleSourceNotInitialized, err)
+}
+
+func TestBundleSourceUpdateMonotonic(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	bundleAt := func(number uint64) *channelconfig.Bundle {
+		block := encoder.New(conf).GenesisBlockForChannel("testchannel")
+		block.Header.Number = number
+		bundle, err := channelconfig.NewBundleFromBlock(block, cryptoProvider)
+		require.NoError(t, err)
+		return bundle
+	}
+
+	unnumbered := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSource(unnumbered)
+
+	var updates int
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+		updates++
+	})
+
+	applied, err := bs.UpdateMonotonic(unnumbered)
+	require.EqualError(t, err, "new bundle does not carry its config block number")
+	require.False(t, applied)
+
+	_, err = bs.UpdateMonotonic(nil)
+	require.EqualError(t, err, "new bundle does not carry its config block number")
+
+	fifth := bundleAt(5)
+	applied, err = bs.UpdateMonotonic(fifth)
+	require.NoError(t, err)
+	require.True(t, applied)
+	require.True(t, bs.StableBundle() == fifth)
+
+	for _, number := range []uint64{5, 3} {
+		applied, err = bs.UpdateMonotonic(bundleAt(number))
+		require.NoError(t, err)
+		require.False(t, applied)
+		require.True(t, bs.StableBundle() == fifth)
+	}
+
+	sixth := bundleAt(6)
+	applied, err = bs.UpdateMonotonic(sixth)
+	require.NoError(t, err)
+	require.True(t, applied)
+	require.True(t, bs.StableBundle() == sixth)
+	require.Equal(t, 2, updates)
+	require.Equal(t, uint64(3), bs.Sequence())
+
+	empty := &channelconfig.BundleSource{}
+	applied, err = empty.UpdateMonotonic(fifth)
+	require.NoError(t, err)
+	require.True(t, applied)
+	require.True(t, empty.StableBundle() == fifth)
+}
+
+func TestBundleSourceCompareAndUpdate(t *testing.T) {
+	first, second, third := &channelconfig.Bundle{}, &channelconfig.Bundle{}, &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(first)
+
+	var updates int
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+		updates++
+	})
+
+	bundle, sequence := bs.SequencedBundle()
+	require.True(t, bundle == first)
+
+	applied, err := bs.CompareAndUpdate(sequence, second)
+	require.NoError(t, err)
+	require.True(t, applied)
+	require.True(t, bs.StableBundle() == second)
+
+	applied, err = bs.CompareAndUpdate(sequence, third)
+	require.NoError(t, err)
+	require.False(t, applied)
+	require.True(t, bs.StableBundle() == second)
+	require.Equal(t, 1, updates)
+
+	_, err = bs.CompareAndUpdate(bs.Sequence(), nil)
+	require.EqualError(t, err, "new bundle cannot be nil")
+
+	t.Run("Concurrent", func(t *testing.T) {
+		bs := channelconfig.NewBundleSource(first)
+		sequence := bs.Sequence()
+
+		var wg sync.WaitGroup
+		results := make([]bool, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = bs.CompareAndUpdate(sequence, &channelconfig.Bundle{})
+			}(i)
+		}
+		wg.Wait()
+
+		var applied int
+		for _, result := range results {
+			if result {
+				applied++
+			}
+		}
+		require.Equal(t, 1, applied)
+		require.Equal(t, sequence+1, bs.Sequence())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		empty := &channelconfig.BundleSource{}
+		applied, err := empty.CompareAndUpdate(1, first)
+		require.NoError(t, err)
+		require.False(t, applied)
+
+		applied, err = empty.CompareAndUpdate(0, first)
+		require.NoError(t, err)
+		require.True(t, applied)
+		require.True(t, empty.StableBundle() == first)
+	})
+}
+
+func TestBundleSourceRLock(t *testing.T) {
+	initial, next := &channelconfig.Bundle{}, &channelconfig.Bundle{}
+	bs := channelconfig.NewBundleSource(initial)
+
+	listenerLocked := make(chan struct{})
+	bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+		bs.RLock()
+		defer bs.RUnlock()
+		close(listenerLocked)
+	})
+
+	bs.RLock()
+	updated := make(chan struct{})
+	go func() {
+		bs.Update(next)
+		close(updated)
+	}()
+
+	select {
+	case <-updated:
+		t.Fatal("update must wait for RUnlock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.True(t, bs.StableBundle() == initial)
+
+	bs.RUnlock()
+	<-updated
+	<-listenerLocked
+	require.True(t, bs.StableBundle() == next)
+
+	t.Run("Lazy", func(t *testing.T) {
+		bs := channelconfig.NewLazyBundleSource(func() (*channelconfig.Bundle, error) {
+			return initial, nil
+		})
+		bs.RLock()
+		defer bs.RUnlock()
+		require.True(t, bs.StableBundle() == initial)
+	})
+}
+
+func TestBundleSourceHistory(t *testing.T) {
+	bundles := make([]*channelconfig.Bundle, 5)
+	for i := range bundles {
+		bundles[i] = &channelconfig.Bundle{}
+	}
+
+	bs := channelconfig.NewBundleSourceWithOptions(bundles[0], channelconfig.WithHistory(3))
+	require.Equal(t, []*channelconfig.Bundle{bundles[0]}, bs.History())
+
+	for _, bundle := range bundles[1:] {
+		bs.Update(bundle)
+	}
+	history := bs.History()
+	require.Len(t, history, 3)
+	for i, bundle := range []*channelconfig.Bundle{bundles[4], bundles[3], bundles[2]} {
+		require.True(t, history[i] == bundle)
+	}
+
+	history[0] = nil
+	require.True(t, bs.History()[0] == bundles[4])
+
+	_, err := bs.Rollback()
+	require.NoError(t, err)
+	history = bs.History()
+	require.Len(t, history, 3)
+	for i, bundle := range []*channelconfig.Bundle{bundles[3], bundles[4], bundles[3]} {
+		require.True(t, history[i] == bundle)
+	}
+
+	require.Nil(t, channelconfig.NewBundleSource(bundles[0]).History())
+	require.Nil(t, channelconfig.NewBundleSourceWithOptions(bundles[0], channelconfig.WithHistory(-1)).History())
+	require.Nil(t, (&channelconfig.BundleSource{}).History())
+}
+
+func TestLazyBundleSource(t *testing.T) {
+	t.Run("ConstructOnce", func(t *testing.T) {
+		initial := &channelconfig.Bundle{}
+		var constructions int32
+		bs := channelconfig.NewLazyBundleSource(func() (*channelconfig.Bundle, error) {
+			atomic.AddInt32(&constructions, 1)
+			return initial, nil
+		})
+
+		var listenerCalls int
+		bs.RegisterUpdateListener(func(oldBundle, newBundle *channelconfig.Bundle) {
+			listenerCalls++
+		})
+		require.Equal(t, int32(0), atomic.LoadInt32(&constructions))
+
+		var wg sync.WaitGroup
+		bundles := make([]*channelconfig.Bundle, 10)
+		for i := range bundles {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				bundles[i] = bs.StableBundle()
+			}(i)
+		}
+		wg.Wait()
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&constructions))
+		require.Equal(t, 1, listenerCalls)
+		for _, bundle := range bundles {
+			require.True(t, bundle == initial)
+		}
+		require.Equal(t, uint64(1), bs.Sequence())
+
+		next := &channelconfig.Bundle{}
+		bs.Update(next)
+		require.True(t, bs.StableBundle() == next)
+		require.Equal(t, int32(1), atomic.LoadInt32(&constructions))
+	})
+
+	t.Run("ConstructionError", func(t *testing.T) {
+		bs := channelconfig.NewLazyBundleSource(func() (*channelconfig.Bundle, error) {
+			return nil, errors.New("construction failed")
+		})
+
+		bundle, err := bs.LoadBundle()
+		require.EqualError(t, err, "construction failed")
+		require.Nil(t, bundle)
+		require.PanicsWithError(t, "construction failed", func() { bs.StableBundle() })
+		_, ok := bs.OrdererConfig()
+		require.False(t, ok)
+		require.Equal(t, uint64(0), bs.Sequence())
+
+		next := &channelconfig.Bundle{}
+		bs.Update(next)
+		require.True(t, bs.StableBundle() == next)
+	})
+
+	t.Run("ConstructionRetried", func(t *testing.T) {
+		initial := &channelconfig.Bundle{}
+		var constructions int
+		bs := channelconfig.NewLazyBundleSource(func() (*channelconfig.Bundle, error) {
+			constructions++
+			if constructions == 1 {
+				return nil, errors.New("transient failure")
+			}
+			return initial, nil
+		})
+
+		_, err := bs.LoadBundle()
+		require.EqualError(t, err, "transient failure")
+
+		require.True(t, bs.StableBundle() == initial)
+		require.Equal(t, uint64(1), bs.Sequence())
+		require.True(t, bs.StableBundle() == initial)
+		require.Equal(t, 2, constructions)
+	})
+
+	t.Run("UpdateBeforeAccess", func(t *testing.T) {
+		bs := channelconfig.NewLazyBundleSource(func() (*channelconfig.Bundle, error) {
+			t.Fatal("factory must not be invoked")
+			return nil, nil
+		})
+
+		next := &channelconfig.Bundle{}
+		bs.Update(next)
+		require.True(t, bs.StableBundle() == next)
+	})
+}
@@ -0,0 +1,43 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+// SetUserData attaches a value derived from the current bundle under the given
+// key, replacing any value previously set under the same key.  Values are
+// scoped to the current bundle generation: they are discarded by the next
+// Update, including a Rollback, so that derived state never outlives the
+// config it was derived from.  As a concurrent Update may replace the bundle
+// between deriving and setting a value, values should be set from an update
+// listener, which observes the new bundle as the current one until it
+// returns.  If the source is not initialized, the value is discarded.
+func (bs *BundleSource) SetUserData(key string, value interface{}) {
+	current, err := bs.load()
+	if err != nil {
+		return
+	}
+
+	current.userDataMutex.Lock()
+	defer current.userDataMutex.Unlock()
+	if current.userData == nil {
+		current.userData = map[string]interface{}{}
+	}
+	current.userData[key] = value
+}
+
+// GetUserData returns the value set via SetUserData under the given key for
+// the current bundle generation, and whether such a value exists.
+func (bs *BundleSource) GetUserData(key string) (interface{}, bool) {
+	current, err := bs.load()
+	if err != nil {
+		return nil, false
+	}
+
+	current.userDataMutex.Lock()
+	defer current.userDataMutex.Unlock()
+	value, ok := current.userData[key]
+	return value, ok
+}
@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceUserData(t *testing.T) {
+	bs := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+
+	_, ok := bs.GetUserData("acls")
+	require.False(t, ok)
+
+	bs.SetUserData("acls", "initial")
+	bs.SetUserData("other", 1)
+	value, ok := bs.GetUserData("acls")
+	require.True(t, ok)
+	require.Equal(t, "initial", value)
+
+	bs.SetUserData("acls", nil)
+	value, ok = bs.GetUserData("acls")
+	require.True(t, ok)
+	require.Nil(t, value)
+
+	newBundle := &channelconfig.Bundle{}
+	bs.RegisterUpdateListener(func(oldBundle, updated *channelconfig.Bundle) {
+		_, ok := bs.GetUserData("other")
+		require.False(t, ok)
+		bs.SetUserData("acls", updated)
+	})
+	bs.Update(newBundle)
+
+	value, ok = bs.GetUserData("acls")
+	require.True(t, ok)
+	require.True(t, value == newBundle)
+	_, ok = bs.GetUserData("other")
+	require.False(t, ok)
+
+	_, err := bs.Rollback()
+	require.NoError(t, err)
+	value, ok = bs.GetUserData("acls")
+	require.True(t, ok)
+	require.False(t, value == newBundle)
+	require.True(t, value == bs.StableBundle())
+
+	uninitialized := &channelconfig.BundleSource{}
+	uninitialized.SetUserData("acls", "value")
+	_, ok = uninitialized.GetUserData("acls")
+	require.False(t, ok)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// GlobalUpdateListener is notified with the channel ID, the previous bundle,
+// and the new bundle whenever the bundle of a BundleSource registered with a
+// BundleSourceRegistry is replaced.
+type GlobalUpdateListener func(channelID string, oldBundle, newBundle *Bundle)
+
+// BundleSourceRegistry tracks the BundleSources of multiple channels by
+// channel ID.  It is safe for concurrent use.
+type BundleSourceRegistry struct {
+	mutex     sync.RWMutex
+	entries   map[string]*registryEntry
+	listeners []GlobalUpdateListener
+}
+
+// registryEntry is created for every registration, so that listeners
+// installed on a BundleSource stay inactive once it has been removed, even if
+// the same BundleSource is registered again.
+type registryEntry struct {
+	bundleSource *BundleSource
+}
+
+// NewBundleSourceRegistry creates an empty BundleSourceRegistry.
+func NewBundleSourceRegistry() *BundleSourceRegistry {
+	return &BundleSourceRegistry{
+		entries: map[string]*registryEntry{},
+	}
+}
+
+// Register registers the BundleSource of the given channel, replacing any
+// BundleSource previously registered for it.
+func (r *BundleSourceRegistry) Register(channelID string, bs *BundleSource) {
+	entry := &registryEntry{bundleSource: bs}
+
+	r.mutex.Lock()
+	r.entries[channelID] = entry
+	listeners := append([]GlobalUpdateListener(nil), r.listeners...)
+	r.mutex.Unlock()
+
+	// listeners are installed without holding the registry mutex, as they
+	// acquire it while the mutex of the BundleSource is held
+	for _, listener := range listeners {
+		r.install(channelID, entry, listener)
+	}
+}
+
+// Get returns the BundleSource registered for the given channel, and whether
+// there is one.
+func (r *BundleSourceRegistry) Get(channelID string) (*BundleSource, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, ok := r.entries[channelID]
+	if !ok {
+		return nil, false
+	}
+	return entry.bundleSource, true
+}
+
+// Remove removes the BundleSource registered for the given channel, if any.
+// Global update listeners are no longer notified about its updates.
+func (r *BundleSourceRegistry) Remove(channelID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.entries, channelID)
+}
+
+// Channels returns the sorted IDs of the channels with a registered
+// BundleSource.
+func (r *BundleSourceRegistry) Channels() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	channelIDs := make([]string, 0, len(r.entries))
+	for channelID := range r.entries {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+	return channelIDs
+}
+
+// UpdateAll updates the BundleSources of several channels, keyed by channel
+// ID, as a single batch.  First, all new bundles are validated: each channel
+// must have a registered BundleSource, which must not be registered for
+// another channel of the batch, and each bundle must be non-nil, built for its
+// channel, and valid to be derived from the current bundle of the channel as
+// checked by Bundle.ValidateNew.  If any of these checks fails, an error is
+// returned and none of the BundleSources is updated.
+//
+// Otherwise, all BundleSources of the batch are locked, in order of channel
+// ID, so that no other update of them can interleave with the batch, and the
+// bundles are stored in the same order.  Callbacks and listeners, including
+// global update listeners, are invoked only after all bundles have been
+// stored, so they always observe the complete batch.  As every BundleSource
+// still has its own atomic pointer, readers accessing several BundleSources
+// while UpdateAll is running may observe the bundles of channels preceding in
+// channel ID order already updated and the remaining ones not yet.
+func (r *BundleSourceRegistry) UpdateAll(updates map[string]*Bundle) error {
+	channelIDs := make([]string, 0, len(updates))
+	for channelID := range updates {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+
+	sources := make([]*BundleSource, len(channelIDs))
+	owners := map[*BundleSource]string{}
+	r.mutex.RLock()
+	for i, channelID := range channelIDs {
+		entry, ok := r.entries[channelID]
+		if !ok {
+			r.mutex.RUnlock()
+			return errors.Errorf("no bundle source registered for channel %s", channelID)
+		}
+		if owner, ok := owners[entry.bundleSource]; ok {
+			r.mutex.RUnlock()
+			return errors.Errorf("bundle source of channel %s is also registered for channel %s", channelID, owner)
+		}
+		owners[entry.bundleSource] = channelID
+		sources[i] = entry.bundleSource
+	}
+	r.mutex.RUnlock()
+
+	for _, bs := range sources {
+		bs.mutex.Lock()
+		defer bs.mutex.Unlock()
+	}
+
+	for i, channelID := range channelIDs {
+		if err := validateBatchUpdate(channelID, sources[i].current(), updates[channelID]); err != nil {
+			return err
+		}
+	}
+
+	generations := make([]*bundleGeneration, len(sources))
+	for i, bs := range sources {
+		generations[i] = bs.store(updates[channelIDs[i]])
+	}
+	for i, bs := range sources {
+		bs.announce(generations[i])
+	}
+	return nil
+}
+
+func validateBatchUpdate(channelID string, current *bundleGeneration, newBundle *Bundle) error {
+	if newBundle == nil {
+		return errors.Errorf("new bundle for channel %s cannot be nil", channelID)
+	}
+	if id := newBundle.channelID(); id != "" && id != channelID {
+		return errors.Errorf("new bundle for channel %s was built for channel %s", channelID, id)
+	}
+	if current == nil || current.bundle.channelConfig == nil || newBundle.channelConfig == nil {
+		return nil
+	}
+	if err := current.bundle.ValidateNew(newBundle); err != nil {
+		return errors.WithMessagef(err, "new bundle for channel %s is not valid", channelID)
+	}
+	return nil
+}
+
+// RegisterGlobalUpdateListener registers a listener which is invoked on
+// every subsequent update of any BundleSource registered now or later, for as
+// long as the BundleSource remains registered.  The same restrictions as for
+// update listeners apply; in addition, the listener must not call Register,
+// Remove, or RegisterGlobalUpdateListener, as registering with a BundleSource
+// which is being updated would deadlock.
+func (r *BundleSourceRegistry) RegisterGlobalUpdateListener(listener GlobalUpdateListener) {
+	r.mutex.Lock()
+	r.listeners = append(r.listeners, listener)
+	entries := make(map[string]*registryEntry, len(r.entries))
+	for channelID, entry := range r.entries {
+		entries[channelID] = entry
+	}
+	r.mutex.Unlock()
+
+	for channelID, entry := range entries {
+		r.install(channelID, entry, listener)
+	}
+}
+
+func (r *BundleSourceRegistry) install(channelID string, entry *registryEntry, listener GlobalUpdateListener) {
+	entry.bundleSource.RegisterUpdateListener(func(oldBundle, newBundle *Bundle) {
+		if oldBundle == nil || !r.registered(channelID, entry) {
+			return
+		}
+		listener(channelID, oldBundle, newBundle)
+	})
+}
+
+func (r *BundleSourceRegistry) registered(channelID string, entry *registryEntry) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.entries[channelID] == entry
+}
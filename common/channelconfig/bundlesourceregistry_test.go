@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceRegistry(t *testing.T) {
+	r := channelconfig.NewBundleSourceRegistry()
+	require.Empty(t, r.Channels())
+
+	bs1 := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	bs2 := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	r.Register("channel2", bs2)
+	r.Register("channel1", bs1)
+	require.Equal(t, []string{"channel1", "channel2"}, r.Channels())
+
+	bs, ok := r.Get("channel1")
+	require.True(t, ok)
+	require.True(t, bs == bs1)
+	_, ok = r.Get("missing")
+	require.False(t, ok)
+
+	r.Remove("channel2")
+	require.Equal(t, []string{"channel1"}, r.Channels())
+	_, ok = r.Get("channel2")
+	require.False(t, ok)
+}
+
+func TestBundleSourceRegistryGlobalUpdateListener(t *testing.T) {
+	r := channelconfig.NewBundleSourceRegistry()
+
+	type update struct {
+		channelID            string
+		oldBundle, newBundle *channelconfig.Bundle
+	}
+	var updates []update
+	bs1 := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	r.Register("channel1", bs1)
+	r.RegisterGlobalUpdateListener(func(channelID string, oldBundle, newBundle *channelconfig.Bundle) {
+		updates = append(updates, update{channelID: channelID, oldBundle: oldBundle, newBundle: newBundle})
+	})
+	bs2 := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	r.Register("channel2", bs2)
+
+	old1, next1 := bs1.StableBundle(), &channelconfig.Bundle{}
+	bs1.Update(next1)
+	old2, next2 := bs2.StableBundle(), &channelconfig.Bundle{}
+	bs2.Update(next2)
+	require.Len(t, updates, 2)
+	require.Equal(t, "channel1", updates[0].channelID)
+	require.True(t, updates[0].oldBundle == old1)
+	require.True(t, updates[0].newBundle == next1)
+	require.Equal(t, "channel2", updates[1].channelID)
+	require.True(t, updates[1].oldBundle == old2)
+	require.True(t, updates[1].newBundle == next2)
+
+	r.Remove("channel1")
+	bs1.Update(&channelconfig.Bundle{})
+	require.Len(t, updates, 2)
+
+	// registering the same source again must not duplicate notifications
+	r.Register("channel1", bs1)
+	bs1.Update(&channelconfig.Bundle{})
+	require.Len(t, updates, 3)
+
+	// a source replaced by another one is no longer reported
+	r.Register("channel2", channelconfig.NewBundleSource(&channelconfig.Bundle{}))
+	bs2.Update(&channelconfig.Bundle{})
+	require.Len(t, updates, 3)
+}
+
+func TestBundleSourceRegistryConcurrency(t *testing.T) {
+	r := channelconfig.NewBundleSourceRegistry()
+
+	var mutex sync.Mutex
+	updates := map[string]int{}
+	r.RegisterGlobalUpdateListener(func(channelID string, oldBundle, newBundle *channelconfig.Bundle) {
+		mutex.Lock()
+		updates[channelID]++
+		mutex.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			channelID := fmt.Sprintf("channel%d", i)
+			bs := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+			r.Register(channelID, bs)
+			r.RegisterGlobalUpdateListener(func(string, *channelconfig.Bundle, *channelconfig.Bundle) {})
+			bs.Update(&channelconfig.Bundle{})
+			r.Get(channelID)
+			r.Channels()
+		}(i)
+	}
+	wg.Wait()
+
+	require.Len(t, r.Channels(), 10)
+	for i := 0; i < 10; i++ {
+		require.Equal(t, 1, updates[fmt.Sprintf("channel%d", i)])
+	}
+}
+
+func TestBundleSourceRegistryUpdateAll(t *testing.T) {
+	r := channelconfig.NewBundleSourceRegistry()
+	bs1 := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	bs2 := channelconfig.NewBundleSource(&channelconfig.Bundle{})
+	r.Register("channel1", bs1)
+	r.Register("channel2", bs2)
+
+	next1, next2 := &channelconfig.Bundle{}, &channelconfig.Bundle{}
+	var observed [][2]*channelconfig.Bundle
+	r.RegisterGlobalUpdateListener(func(channelID string, oldBundle, newBundle *channelconfig.Bundle) {
+		observed = append(observed, [2]*channelconfig.Bundle{bs1.StableBundle(), bs2.StableBundle()})
+	})
+
+	require.NoError(t, r.UpdateAll(map[string]*channelconfig.Bundle{"channel1": next1, "channel2": next2}))
+	require.True(t, bs1.StableBundle() == next1)
+	require.True(t, bs2.StableBundle() == next2)
+	require.Len(t, observed, 2)
+	for _, bundles := range observed {
+		require.True(t, bundles[0] == next1)
+		require.True(t, bundles[1] == next2)
+	}
+	require.NoError(t, r.UpdateAll(nil))
+
+	t.Run("Unregistered", func(t *testing.T) {
+		err := r.UpdateAll(map[string]*channelconfig.Bundle{"channel1": {}, "missing": {}})
+		require.EqualError(t, err, "no bundle source registered for channel missing")
+		require.True(t, bs1.StableBundle() == next1)
+	})
+
+	t.Run("NilBundle", func(t *testing.T) {
+		err := r.UpdateAll(map[string]*channelconfig.Bundle{"channel1": {}, "channel2": nil})
+		require.EqualError(t, err, "new bundle for channel channel2 cannot be nil")
+		require.True(t, bs1.StableBundle() == next1)
+	})
+
+	t.Run("SharedBundleSource", func(t *testing.T) {
+		r := channelconfig.NewBundleSourceRegistry()
+		r.Register("channel1", bs1)
+		r.Register("channel2", bs1)
+		err := r.UpdateAll(map[string]*channelconfig.Bundle{"channel1": {}, "channel2": {}})
+		require.EqualError(t, err, "bundle source of channel channel2 is also registered for channel channel1")
+	})
+
+	t.Run("InvalidBundle", func(t *testing.T) {
+		r := channelconfig.NewBundleSourceRegistry()
+		systemChannel := channelconfig.NewBundleSource(newTestBundle(t, genesisconfig.SampleDevModeSoloProfile))
+		r.Register("channel1", bs1)
+		r.Register("testchannel", systemChannel)
+
+		err := r.UpdateAll(map[string]*channelconfig.Bundle{
+			"channel1":    {},
+			"testchannel": newTestBundle(t, genesisconfig.SampleSingleMSPChannelProfile),
+		})
+		require.EqualError(t, err, "new bundle for channel testchannel is not valid: current config has orderer section, but new config does not")
+		require.True(t, bs1.StableBundle() == next1)
+		require.Equal(t, uint64(1), systemChannel.Sequence())
+
+		err = r.UpdateAll(map[string]*channelconfig.Bundle{
+			"channel1": newTestBundle(t, genesisconfig.SampleDevModeSoloProfile),
+		})
+		require.EqualError(t, err, "new bundle for channel channel1 was built for channel testchannel")
+	})
+}
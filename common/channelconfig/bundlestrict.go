@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// WithStrictUnknownFields causes NewBundle to reject configs containing
+// elements which it does not understand and would otherwise ignore, such as a
+// config produced by a newer version of the tooling, with a
+// *MalformedConfigError listing all of them.  These are values of the
+// Consortiums group, and fields of config values, including the Fabric MSP
+// definitions, which are unknown to the protos of this binary.  Unknown groups
+// and unknown values of the other groups are rejected regardless.
+func WithStrictUnknownFields() BundleOption {
+	return func(opts *bundleOptions) {
+		opts.strictUnknownFields = true
+	}
+}
+
+// unknownElementsError returns an error listing the elements of the config
+// which are rejected by WithStrictUnknownFields, or nil if there are none.
+func unknownElementsError(config *cb.Config) error {
+	var unknown []string
+
+	var walk func(path string, group *cb.ConfigGroup)
+	walk = func(path string, group *cb.ConfigGroup) {
+		for _, key := range sortedValueKeys(group.Values) {
+			valuePath := path + policies.PathSeparator + key
+			if path == policies.PathSeparator+RootGroupKey+policies.PathSeparator+ConsortiumsGroupKey {
+				unknown = append(unknown, "value "+valuePath)
+				continue
+			}
+			if hasUnknownFields(key, group.Values[key].Value) {
+				unknown = append(unknown, "fields of value "+valuePath)
+			}
+		}
+		for _, name := range sortedGroupNames(group.Groups) {
+			walk(path+policies.PathSeparator+name, group.Groups[name])
+		}
+	}
+	walk(policies.PathSeparator+RootGroupKey, config.ChannelGroup)
+
+	if len(unknown) == 0 {
+		return nil
+	}
+	return errors.Errorf("config contains unknown elements: %s", strings.Join(unknown, ", "))
+}
+
+// hasUnknownFields returns whether the config value with the given key has
+// fields unknown to its proto.  Values of unknown type, or which cannot be
+// decoded, are reported by the builder and not considered here.
+func hasUnknownFields(key string, value []byte) bool {
+	msg := newConfigValueMessage(key)
+	if msg == nil || proto.Unmarshal(value, msg) != nil {
+		return false
+	}
+	if hasUnrecognizedFields(reflect.ValueOf(msg)) {
+		return true
+	}
+
+	mspConfig, ok := msg.(*mspprotos.MSPConfig)
+	if !ok || mspConfig.Type != int32(msp.FABRIC) {
+		return false
+	}
+	fabricConfig := &mspprotos.FabricMSPConfig{}
+	if proto.Unmarshal(mspConfig.Config, fabricConfig) != nil {
+		return false
+	}
+	return hasUnrecognizedFields(reflect.ValueOf(fabricConfig))
+}
+
+// hasUnrecognizedFields returns whether the generated proto struct, or any
+// message nested in it, retained fields it could not decode.
+func hasUnrecognizedFields(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil() && hasUnrecognizedFields(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Name
+			if name == "XXX_unrecognized" {
+				if v.Field(i).Len() > 0 {
+					return true
+				}
+				continue
+			}
+			if !strings.HasPrefix(name, "XXX_") && hasUnrecognizedFields(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if hasUnrecognizedFields(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if hasUnrecognizedFields(v.MapIndex(key)) {
+				return true
+			}
+		}
+	}
+	return false
+}
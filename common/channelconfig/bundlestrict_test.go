@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStrictUnknownFields(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	// unknownField encodes varint field 99, which no config proto defines.
+	unknownField := []byte{0x98, 0x06, 0x01}
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	config := &cb.Config{ChannelGroup: cg}
+
+	bundle, err := channelconfig.NewBundle("foo", config, cryptoProvider, channelconfig.WithStrictUnknownFields())
+	require.NoError(t, err)
+	require.NotNil(t, bundle)
+
+	batchSize := cg.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.BatchSizeKey]
+	batchSize.Value = append(batchSize.Value, unknownField...)
+
+	// SampleOrg is defined in several groups, and all definitions of an MSP
+	// must be identical.
+	for _, orgGroup := range []*cb.ConfigGroup{
+		cg.Groups[channelconfig.OrdererGroupKey].Groups["SampleOrg"],
+		cg.Groups[channelconfig.ApplicationGroupKey].Groups["SampleOrg"],
+		cg.Groups[channelconfig.ConsortiumsGroupKey].Groups["SampleConsortium"].Groups["SampleOrg"],
+	} {
+		mspValue := orgGroup.Values[channelconfig.MSPKey]
+		mspConfig := &mspprotos.MSPConfig{}
+		require.NoError(t, proto.Unmarshal(mspValue.Value, mspConfig))
+		mspConfig.Config = append(mspConfig.Config, unknownField...)
+		mspValue.Value = protoutil.MarshalOrPanic(mspConfig)
+	}
+
+	cg.Groups[channelconfig.ConsortiumsGroupKey].Values = map[string]*cb.ConfigValue{
+		"FutureValue": {Value: []byte("value"), ModPolicy: "/Channel/Orderer/Admins"},
+	}
+
+	_, err = channelconfig.NewBundle("foo", config, cryptoProvider)
+	require.NoError(t, err)
+
+	_, err = channelconfig.NewBundle("foo", config, cryptoProvider, channelconfig.WithStrictUnknownFields())
+	var malformed *channelconfig.MalformedConfigError
+	require.True(t, errors.As(err, &malformed))
+	require.EqualError(t, err, "config contains unknown elements: "+
+		"fields of value /Channel/Application/SampleOrg/MSP, "+
+		"value /Channel/Consortiums/FutureValue, "+
+		"fields of value /Channel/Consortiums/SampleConsortium/SampleOrg/MSP, "+
+		"fields of value /Channel/Orderer/BatchSize, "+
+		"fields of value /Channel/Orderer/SampleOrg/MSP")
+}
@@ -0,0 +1,488 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/pkg/errors"
+)
+
+// ValidateConfig runs the checks performed by NewBundle on the config,
+// including those which NewBundle only performs when enabled by a
+// BundleOption, such as WithPolicyReferenceValidation, and additionally checks
+// that the capabilities of the Channel, Orderer, and Application groups are
+// supported, as ValidateCapabilities does.  Unlike
+// NewBundle, which stops at the first problem, it returns every problem
+// found, so that an operator authoring a config can fix them all at once.
+// The errors are of the types returned by NewBundle.
+//
+// The sections of the Channel group are checked independently of each other,
+// but the problems within a section are still reported one at a time.
+// Policy and MSP references, orderer endpoints, and the uniqueness of MSP IDs
+// are checked on the bundle built from the config, and are therefore only
+// checked if no section is malformed.
+func ValidateConfig(channelID string, config *cb.Config, bccsp bccsp.BCCSP) []error {
+	errs := preValidationErrors(config)
+	if config == nil || config.ChannelGroup == nil {
+		return errs
+	}
+	channelGroup := config.ChannelGroup
+
+	cc := &ChannelConfig{protos: &ChannelProtos{}}
+	if err := DeserializeProtoValuesFromGroup(channelGroup, cc.protos); err != nil {
+		return append(errs, &MalformedConfigError{Err: errors.Wrap(err, "failed to deserialize values")})
+	}
+
+	capabilities := cc.Capabilities()
+	if err := capabilities.Supported(); err != nil {
+		errs = append(errs, &UnsupportedCapabilityError{Err: err})
+	}
+	validators := []func() error{cc.validateHashingAlgorithm, cc.validateBlockDataHashingStructure}
+	if !capabilities.OrgSpecificOrdererEndpoints() {
+		validators = append(validators, cc.validateOrdererAddresses)
+	}
+	for _, validator := range validators {
+		if err := validator(); err != nil {
+			errs = append(errs, &MalformedConfigError{Err: err})
+		}
+	}
+
+	mspConfigHandler := NewMSPConfigHandler(capabilities.MSPVersion(), bccsp)
+	var malformed bool
+	for _, groupName := range sortedGroupNames(channelGroup.Groups) {
+		group := channelGroup.Groups[groupName]
+
+		var err error
+		var supported func() error
+		switch groupName {
+		case ApplicationGroupKey:
+			if cc.appConfig, err = NewApplicationConfig(group, mspConfigHandler); err == nil {
+				supported = cc.appConfig.Capabilities().Supported
+			}
+		case OrdererGroupKey:
+			if cc.ordererConfig, err = NewOrdererConfig(group, mspConfigHandler, capabilities); err == nil {
+				supported = cc.ordererConfig.Capabilities().Supported
+			}
+		case ConsortiumsGroupKey:
+			cc.consortiumsConfig, err = NewConsortiumsConfig(group, mspConfigHandler)
+		default:
+			err = errors.Errorf("Disallowed channel group: %s", groupName)
+		}
+		if err != nil {
+			malformed = true
+			errs = append(errs, malformedChannelConfigError(withMSPSetupGroup(err, groupName), "could not create channel "+groupName+" sub-group config"))
+			continue
+		}
+		if supported != nil {
+			if err := supported(); err != nil {
+				errs = append(errs, &UnsupportedCapabilityError{Err: err})
+			}
+		}
+	}
+	if malformed {
+		return errs
+	}
+
+	var err error
+	if cc.mspManager, err = mspConfigHandler.CreateMSPManager(); err != nil {
+		return append(errs, &MalformedConfigError{Err: errors.Wrap(err, "initializing channelconfig failed")})
+	}
+
+	b, err := newBundle(channelID, config, cc, bccsp, nil)
+	if err != nil {
+		return append(errs, err)
+	}
+	errs = append(errs, b.mspReferenceErrors()...)
+	errs = append(errs, b.policyReferenceErrors()...)
+	errs = append(errs, b.ordererEndpointErrors()...)
+	return append(errs, b.duplicateMSPIDErrors()...)
+}
+
+// WithMSPReferenceValidation causes NewBundle to reject configs with orgs
+// whose MSP ID does not resolve, see ValidateMSPReferences.
+func WithMSPReferenceValidation() BundleOption {
+	return func(opts *bundleOptions) {
+		opts.validateMSPReferences = true
+	}
+}
+
+// ValidateMSPReferences checks that the MSP ID of every orderer, application,
+// and consortium org resolves to an MSP in the MSP manager of the bundle, and
+// returns an *UnknownMSPError otherwise.
+func (b *Bundle) ValidateMSPReferences() error {
+	return firstError(b.mspReferenceErrors())
+}
+
+// mspReferenceErrors returns all problems found by ValidateMSPReferences.
+func (b *Bundle) mspReferenceErrors() []error {
+	msps, err := bundleMSPs(b)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, org := range b.organizations() {
+		if _, ok := msps[org.MSPID()]; !ok {
+			errs = append(errs, &UnknownMSPError{OrgName: org.Name(), MSPID: org.MSPID()})
+		}
+	}
+	return errs
+}
+
+// WithPolicyReferenceValidation causes NewBundle to reject configs with policy
+// references which do not resolve, see ValidatePolicyReferences.
+func WithPolicyReferenceValidation() BundleOption {
+	return func(opts *bundleOptions) {
+		opts.validatePolicyReferences = true
+	}
+}
+
+// ValidatePolicyReferences checks that every policy reference in the channel
+// group and in the Orderer and Application groups resolves to a policy in the
+// policy manager of the bundle.  These are the mod policies of all groups,
+// values, and policies, which are resolved like the config update validation
+// does, and the policy references of the application ACLs.  Empty mod
+// policies are not considered, as they mark an element as unmodifiable.  A
+// reference which does not resolve is reported as a *DanglingPolicyError.
+func (b *Bundle) ValidatePolicyReferences() error {
+	return firstError(b.policyReferenceErrors())
+}
+
+// policyReferenceErrors returns all problems found by
+// ValidatePolicyReferences.
+func (b *Bundle) policyReferenceErrors() []error {
+	channelGroup := b.ConfigProto().GetChannelGroup()
+	if channelGroup == nil {
+		return nil
+	}
+
+	errs := groupPolicyReferenceErrors(b.policyManager, nil, channelGroup, false)
+	for _, groupName := range []string{OrdererGroupKey, ApplicationGroupKey} {
+		if group, ok := channelGroup.Groups[groupName]; ok {
+			errs = append(errs, groupPolicyReferenceErrors(b.policyManager, []string{groupName}, group, true)...)
+		}
+	}
+
+	if ac := b.channelConfig.ApplicationConfig(); ac != nil {
+		acls := ac.protos.ACLs.GetAcls()
+		for _, name := range sortedACLNames(acls) {
+			policyRef := acls[name].PolicyRef
+			if policyRef == "" {
+				continue
+			}
+			if !strings.HasPrefix(policyRef, policies.PathSeparator) {
+				policyRef = policies.PathSeparator + ChannelGroupKey + policies.PathSeparator + ApplicationGroupKey + policies.PathSeparator + policyRef
+			}
+			if _, ok := b.policyManager.GetPolicy(policyRef); !ok {
+				errs = append(errs, &DanglingPolicyError{Referrer: "ACL " + name, PolicyName: acls[name].PolicyRef})
+			}
+		}
+	}
+
+	return errs
+}
+
+// WithOrdererEndpointValidation causes NewBundle to reject configs with
+// malformed orderer addresses or endpoints, see ValidateOrdererEndpoints.
+func WithOrdererEndpointValidation() BundleOption {
+	return func(opts *bundleOptions) {
+		opts.validateOrdererEndpoints = true
+	}
+}
+
+// ValidateOrdererEndpoints checks that the global orderer addresses of the
+// channel group and the endpoints of every orderer org are of the form
+// host:port, where the host is a DNS name, an IPv4 address, or an IPv6 address
+// enclosed in brackets, and the port is a number between 1 and 65535, and
+// returns a *MalformedConfigError naming the first malformed address
+// otherwise.
+func (b *Bundle) ValidateOrdererEndpoints() error {
+	return firstError(b.ordererEndpointErrors())
+}
+
+// ordererEndpointErrors returns all problems found by
+// ValidateOrdererEndpoints.
+func (b *Bundle) ordererEndpointErrors() []error {
+	if b.channelConfig == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, address := range b.channelConfig.OrdererAddresses() {
+		if err := validateEndpoint(address); err != nil {
+			errs = append(errs, &MalformedConfigError{Err: errors.WithMessagef(err, "invalid orderer address %q", address)})
+		}
+	}
+
+	if oc, ok := b.OrdererConfig(); ok {
+		orgs := oc.Organizations()
+		orgNames := make([]string, 0, len(orgs))
+		for orgName := range orgs {
+			orgNames = append(orgNames, orgName)
+		}
+		sort.Strings(orgNames)
+
+		for _, orgName := range orgNames {
+			for _, endpoint := range orgs[orgName].Endpoints() {
+				if err := validateEndpoint(endpoint); err != nil {
+					errs = append(errs, &MalformedConfigError{Err: errors.WithMessagef(err, "invalid orderer endpoint %q of org %s", endpoint, orgName)})
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// WithUniqueMSPIDValidation causes NewBundle to reject configs in which orgs
+// of different names share an MSP ID, see ValidateUniqueMSPIDs.
+func WithUniqueMSPIDValidation() BundleOption {
+	return func(opts *bundleOptions) {
+		opts.validateUniqueMSPIDs = true
+	}
+}
+
+// ValidateUniqueMSPIDs checks that no MSP ID is claimed by orgs of different
+// names, across the Orderer, Application, and Consortiums groups, and returns
+// a *DuplicateMSPIDError naming the orgs sharing the first such MSP ID
+// otherwise.  An org appearing under the same name in several groups, e.g. in
+// a consortium and in the Application group, is the same org and may use the
+// same MSP ID in each of them.
+func (b *Bundle) ValidateUniqueMSPIDs() error {
+	return firstError(b.duplicateMSPIDErrors())
+}
+
+// duplicateMSPIDErrors returns all problems found by ValidateUniqueMSPIDs,
+// sorted by MSP ID.
+func (b *Bundle) duplicateMSPIDErrors() []error {
+	orgNames := map[string][]string{}
+	for _, org := range b.organizations() {
+		names := orgNames[org.MSPID()]
+		if !containsString(names, org.Name()) {
+			orgNames[org.MSPID()] = append(names, org.Name())
+		}
+	}
+
+	mspIDs := make([]string, 0, len(orgNames))
+	for mspID := range orgNames {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+
+	var errs []error
+	for _, mspID := range mspIDs {
+		if names := orgNames[mspID]; len(names) > 1 {
+			sort.Strings(names)
+			errs = append(errs, &DuplicateMSPIDError{MSPID: mspID, OrgNames: names})
+		}
+	}
+	return errs
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEndpoint checks that the endpoint is of the form host:port, as
+// described for ValidateOrdererEndpoints.
+func validateEndpoint(endpoint string) error {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return errors.Errorf("invalid port %q", port)
+	}
+
+	if strings.Contains(host, ":") {
+		if net.ParseIP(host) == nil {
+			return errors.Errorf("invalid IPv6 address %q", host)
+		}
+		return nil
+	}
+	if !isDNSName(host) {
+		return errors.Errorf("invalid host %q", host)
+	}
+	return nil
+}
+
+// isDNSName returns whether the host consists of dot separated labels of at
+// most 63 letters, digits, hyphens, and underscores, which neither start nor
+// end with a hyphen.  IPv4 addresses are of this form as well.
+func isDNSName(host string) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// groupPolicyReferenceErrors checks the mod policies of the group at the
+// given path, relative to the channel group, and of its values and policies.
+// With recurse set, the sub-groups are checked as well.
+func groupPolicyReferenceErrors(policyManager policies.Manager, path []string, group *cb.ConfigGroup, recurse bool) []error {
+	groupPath := policies.PathSeparator + strings.Join(append([]string{ChannelGroupKey}, path...), policies.PathSeparator)
+	manager, ok := policyManager.Manager(path)
+	if !ok {
+		return []error{errors.Errorf("no policy manager for group %s", groupPath)}
+	}
+
+	resolves := func(modPolicy string) bool {
+		if modPolicy == "" {
+			return true
+		}
+		if strings.HasPrefix(modPolicy, policies.PathSeparator) {
+			_, ok := policyManager.GetPolicy(modPolicy)
+			return ok
+		}
+		_, ok := manager.GetPolicy(modPolicy)
+		return ok
+	}
+
+	var errs []error
+	if !resolves(group.ModPolicy) {
+		errs = append(errs, &DanglingPolicyError{Referrer: "mod_policy of group " + groupPath, PolicyName: group.ModPolicy})
+	}
+	for _, key := range sortedValueKeys(group.Values) {
+		if modPolicy := group.Values[key].ModPolicy; !resolves(modPolicy) {
+			errs = append(errs, &DanglingPolicyError{Referrer: "mod_policy of value " + groupPath + policies.PathSeparator + key, PolicyName: modPolicy})
+		}
+	}
+	for _, key := range sortedKeys(group.Policies) {
+		if modPolicy := group.Policies[key].ModPolicy; !resolves(modPolicy) {
+			errs = append(errs, &DanglingPolicyError{Referrer: "mod_policy of policy " + groupPath + policies.PathSeparator + key, PolicyName: modPolicy})
+		}
+	}
+
+	if !recurse {
+		return errs
+	}
+
+	for _, name := range sortedGroupNames(group.Groups) {
+		childPath := append(append([]string{}, path...), name)
+		errs = append(errs, groupPolicyReferenceErrors(policyManager, childPath, group.Groups[name], true)...)
+	}
+	return errs
+}
+
+func firstError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+func sortedGroupNames(groups map[string]*cb.ConfigGroup) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedValueKeys(values map[string]*cb.ConfigValue) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedACLNames(acls map[string]*pb.APIResource) []string {
+	names := make([]string, 0, len(acls))
+	for name := range acls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// organizations returns the orderer, application, and consortium orgs of the
+// bundle, in this order.  Within each group, orgs are sorted by consortium
+// name and org name.
+func (b *Bundle) organizations() []Org {
+	var result []Org
+	b.walkOrgs(func(group, consortiumName string, org Org) {
+		result = append(result, org)
+	})
+	return result
+}
+
+// walkOrgs invokes fn for the orgs of the bundle in the order of
+// organizations, along with the group and, for consortium orgs, the
+// consortium name of each.
+func (b *Bundle) walkOrgs(fn func(group, consortiumName string, org Org)) {
+	visit := func(group, consortiumName string, orgs []Org) {
+		for _, org := range sortOrgs(orgs) {
+			fn(group, consortiumName, org)
+		}
+	}
+
+	if oc, ok := b.OrdererConfig(); ok {
+		var orgs []Org
+		for _, org := range oc.Organizations() {
+			orgs = append(orgs, org)
+		}
+		visit(OrdererGroupKey, "", orgs)
+	}
+
+	if ac, ok := b.ApplicationConfig(); ok {
+		var orgs []Org
+		for _, org := range ac.Organizations() {
+			orgs = append(orgs, org)
+		}
+		visit(ApplicationGroupKey, "", orgs)
+	}
+
+	if cc, ok := b.ConsortiumsConfig(); ok {
+		consortiums := cc.Consortiums()
+		consortiumNames := make([]string, 0, len(consortiums))
+		for consortiumName := range consortiums {
+			consortiumNames = append(consortiumNames, consortiumName)
+		}
+		sort.Strings(consortiumNames)
+
+		for _, consortiumName := range consortiumNames {
+			var orgs []Org
+			for _, org := range consortiums[consortiumName].Organizations() {
+				orgs = append(orgs, org)
+			}
+			visit(ConsortiumsGroupKey, consortiumName, orgs)
+		}
+	}
+}
+
+func sortOrgs(orgs []Org) []Org {
+	sort.Slice(orgs, func(i, j int) bool {
+		return orgs[i].Name() < orgs[j].Name()
+	})
+	return orgs
+}
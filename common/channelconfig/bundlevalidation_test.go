@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMSPReferences(t *testing.T) {
+	mspManager := msp.NewMSPManager()
+	require.NoError(t, mspManager.Setup(nil))
+
+	b := &Bundle{
+		channelConfig: &ChannelConfig{
+			mspManager: mspManager,
+			appConfig: &ApplicationConfig{
+				applicationOrgs: map[string]ApplicationOrg{
+					"Org3": &ApplicationOrgConfig{OrganizationConfig: &OrganizationConfig{name: "Org3", mspID: "Org3MSP"}},
+				},
+			},
+		},
+	}
+	err := b.ValidateMSPReferences()
+	require.EqualError(t, err, `organization "Org3" references unknown MSPID "Org3MSP"`)
+	require.Equal(t, &UnknownMSPError{OrgName: "Org3", MSPID: "Org3MSP"}, err)
+
+	b.channelConfig.appConfig.applicationOrgs = map[string]ApplicationOrg{}
+	require.NoError(t, b.ValidateMSPReferences())
+}
+
+func TestValidateOrdererEndpoints(t *testing.T) {
+	newBundle := func(addresses []string, orgEndpoints map[string][]string) *Bundle {
+		orgs := map[string]OrdererOrg{}
+		for orgName, endpoints := range orgEndpoints {
+			orgs[orgName] = &OrdererOrgConfig{
+				OrganizationConfig: &OrganizationConfig{name: orgName},
+				protos:             &OrdererOrgProtos{Endpoints: &cb.OrdererAddresses{Addresses: endpoints}},
+				name:               orgName,
+			}
+		}
+		return &Bundle{
+			channelConfig: &ChannelConfig{
+				protos:        &ChannelProtos{OrdererAddresses: &cb.OrdererAddresses{Addresses: addresses}},
+				ordererConfig: &OrdererConfig{orgs: orgs},
+			},
+		}
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		b := newBundle(
+			[]string{"orderer.example.com:7050", "127.0.0.1:7050"},
+			map[string][]string{"Org1": {"[::1]:7050", "orderer_1:443", "orderer.example.com.:65535"}},
+		)
+		require.NoError(t, b.ValidateOrdererEndpoints())
+		require.NoError(t, (&Bundle{}).ValidateOrdererEndpoints())
+	})
+
+	t.Run("InvalidAddress", func(t *testing.T) {
+		err := newBundle([]string{"orderer.example.com:7050", "orderer.example.com"}, nil).ValidateOrdererEndpoints()
+		require.IsType(t, &MalformedConfigError{}, err)
+		require.EqualError(t, err, `invalid orderer address "orderer.example.com": address orderer.example.com: missing port in address`)
+	})
+
+	t.Run("InvalidOrgEndpoint", func(t *testing.T) {
+		b := newBundle(nil, map[string][]string{
+			"Org1": {"orderer1:7050"},
+			"Org2": {"orderer2:7050", "orderer2:0"},
+			"Org3": {"orderer3"},
+		})
+		require.EqualError(t, b.ValidateOrdererEndpoints(), `invalid orderer endpoint "orderer2:0" of org Org2: invalid port "0"`)
+		require.Len(t, b.ordererEndpointErrors(), 2)
+	})
+
+	for _, tc := range []struct {
+		endpoint string
+		err      string
+	}{
+		{endpoint: "orderer:70500", err: `invalid port "70500"`},
+		{endpoint: "orderer:port", err: `invalid port "port"`},
+		{endpoint: "bad host:7050", err: `invalid host "bad host"`},
+		{endpoint: "-orderer:7050", err: `invalid host "-orderer"`},
+		{endpoint: "orderer..example.com:7050", err: `invalid host "orderer..example.com"`},
+		{endpoint: ":7050", err: `invalid host ""`},
+		{endpoint: "[::zz]:7050", err: `invalid IPv6 address "::zz"`},
+		{endpoint: "::1:7050", err: "address ::1:7050: too many colons in address"},
+	} {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			require.EqualError(t, validateEndpoint(tc.endpoint), tc.err)
+		})
+	}
+}
+
+func TestValidateUniqueMSPIDs(t *testing.T) {
+	newOrg := func(name, mspID string) *OrganizationConfig {
+		return &OrganizationConfig{name: name, mspID: mspID}
+	}
+	b := &Bundle{
+		channelConfig: &ChannelConfig{
+			ordererConfig: &OrdererConfig{
+				orgs: map[string]OrdererOrg{
+					"OrdererOrg": &OrdererOrgConfig{OrganizationConfig: newOrg("OrdererOrg", "Org1MSP"), name: "OrdererOrg"},
+				},
+			},
+			appConfig: &ApplicationConfig{
+				applicationOrgs: map[string]ApplicationOrg{
+					"Org1": &ApplicationOrgConfig{OrganizationConfig: newOrg("Org1", "Org1MSP")},
+					"Org2": &ApplicationOrgConfig{OrganizationConfig: newOrg("Org2", "Org2MSP")},
+				},
+			},
+			consortiumsConfig: &ConsortiumsConfig{
+				consortiums: map[string]Consortium{
+					"SampleConsortium": &ConsortiumConfig{
+						orgs: map[string]Org{
+							"Org2": newOrg("Org2", "Org2MSP"),
+						},
+					},
+				},
+			},
+		},
+	}
+	err := b.ValidateUniqueMSPIDs()
+	require.EqualError(t, err, `MSPID "Org1MSP" is claimed by multiple organizations: OrdererOrg, Org1`)
+	require.Equal(t, &DuplicateMSPIDError{MSPID: "Org1MSP", OrgNames: []string{"OrdererOrg", "Org1"}}, err)
+
+	b.channelConfig.consortiumsConfig.consortiums["SampleConsortium"].(*ConsortiumConfig).orgs["Org3"] = newOrg("Org3", "Org2MSP")
+	require.Len(t, b.duplicateMSPIDErrors(), 2)
+
+	b.channelConfig.ordererConfig.orgs = map[string]OrdererOrg{}
+	delete(b.channelConfig.consortiumsConfig.consortiums["SampleConsortium"].(*ConsortiumConfig).orgs, "Org3")
+	require.NoError(t, b.ValidateUniqueMSPIDs())
+}
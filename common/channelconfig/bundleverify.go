@@ -0,0 +1,43 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/pkg/errors"
+)
+
+// VerifyConfigBlock checks that the config block produces a bundle equivalent
+// to the expected bundle, e.g. to assert that the bundle installed for a
+// config block received from an orderer was not tampered with.  The block is
+// built into a bundle with the crypto provider and options of the expected
+// bundle, and must be for the same channel and have the same Fingerprint.
+// On mismatch, the returned error names the sections which differ.
+func VerifyConfigBlock(block *cb.Block, expected *Bundle) error {
+	if expected == nil {
+		return errors.New("no expected bundle to verify the config block against")
+	}
+
+	actual, err := NewBundleFromBlock(block, expected.bccsp, expected.options...)
+	if err != nil {
+		return errors.WithMessage(err, "could not build bundle from config block")
+	}
+
+	if actual.channelID() != expected.channelID() {
+		return errors.Errorf("config block %d is for channel %q, expected channel %q", block.Header.Number, actual.channelID(), expected.channelID())
+	}
+
+	if actual.Fingerprint() != expected.Fingerprint() {
+		diff := expected.Diff(actual)
+		if diff.Empty() {
+			return errors.Errorf("config block %d does not match the expected bundle: fingerprint %s, expected %s", block.Header.Number, actual.Fingerprint(), expected.Fingerprint())
+		}
+		return errors.Errorf("config block %d does not match the expected bundle: %s", block.Header.Number, diff)
+	}
+
+	return nil
+}
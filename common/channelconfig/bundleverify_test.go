@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyConfigBlock(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	block := encoder.New(conf).GenesisBlockForChannel("foo")
+	expected, err := channelconfig.NewBundleFromBlock(block, cryptoProvider)
+	require.NoError(t, err)
+
+	require.NoError(t, channelconfig.VerifyConfigBlock(block, expected))
+
+	t.Run("NoExpectedBundle", func(t *testing.T) {
+		require.EqualError(t, channelconfig.VerifyConfigBlock(block, nil), "no expected bundle to verify the config block against")
+	})
+
+	t.Run("OtherChannel", func(t *testing.T) {
+		err := channelconfig.VerifyConfigBlock(encoder.New(conf).GenesisBlockForChannel("bar"), expected)
+		require.EqualError(t, err, `config block 0 is for channel "bar", expected channel "foo"`)
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		modified := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		modified.Orderer.BatchTimeout *= 2
+		err := channelconfig.VerifyConfigBlock(encoder.New(modified).GenesisBlockForChannel("foo"), expected)
+		require.EqualError(t, err, "config block 0 does not match the expected bundle: changed sections: Orderer")
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		env := protoutil.ExtractEnvelopeOrPanic(block, 0)
+		payload := protoutil.UnmarshalPayloadOrPanic(env.Payload)
+		configEnv := &cb.ConfigEnvelope{}
+		require.NoError(t, proto.Unmarshal(payload.Data, configEnv))
+		configEnv.Config.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Version++
+		payload.Data = protoutil.MarshalOrPanic(configEnv)
+		env.Payload = protoutil.MarshalOrPanic(payload)
+		tampered := proto.Clone(block).(*cb.Block)
+		tampered.Data.Data[0] = protoutil.MarshalOrPanic(env)
+
+		err := channelconfig.VerifyConfigBlock(tampered, expected)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "config block 0 does not match the expected bundle")
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		err := channelconfig.VerifyConfigBlock(&cb.Block{}, expected)
+		require.EqualError(t, err, "could not build bundle from config block: block header cannot be nil")
+	})
+}
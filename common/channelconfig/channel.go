@@ -107,7 +107,7 @@ func NewChannelConfig(channelGroup *cb.ConfigGroup, bccsp bccsp.BCCSP) (*Channel
 			return nil, fmt.Errorf("Disallowed channel group: %s", group)
 		}
 		if err != nil {
-			return nil, errors.Wrapf(err, "could not create channel %s sub-group config", groupName)
+			return nil, errors.Wrapf(withMSPSetupGroup(err, groupName), "could not create channel %s sub-group config", groupName)
 		}
 	}
 
@@ -148,9 +148,9 @@ func (cc *ChannelConfig) BlockDataHashingStructureWidth() uint32 {
 	return cc.protos.BlockDataHashingStructure.Width
 }
 
-// OrdererAddresses returns the list of valid orderer addresses to connect to to invoke Broadcast/Deliver
+// OrdererAddresses returns a copy of the list of valid orderer addresses to connect to to invoke Broadcast/Deliver
 func (cc *ChannelConfig) OrdererAddresses() []string {
-	return cc.protos.OrdererAddresses.Addresses
+	return copyStrings(cc.protos.OrdererAddresses.Addresses)
 }
 
 // ConsortiumName returns the name of the consortium this channel was created under
@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfigtest
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// ChannelID is the ID of the channel the bundles returned by NewTestBundle are
+// built for.
+const ChannelID = "testchannel"
+
+// NewTestBundle returns a bundle for an application channel whose Application
+// group contains an org for each of the given MSPs, for tests which need a
+// bundle but only exercise MSP and identity logic.  The MSPs are keyed by MSP
+// ID, which is used as the org name as well, and the name of an MSP config
+// must either be empty or match its key.  The channel and the Application
+// group enable the V2_0 capabilities and have implicit meta Readers, Writers,
+// and Admins policies, and each org has signature Readers, Writers, and
+// Endorsement policies satisfied by its members and an Admins policy
+// satisfied by its admins.  The bundle has no Orderer group.
+func NewTestBundle(msps map[string]*mspprotos.FabricMSPConfig) (*channelconfig.Bundle, error) {
+	applicationGroup := protoutil.NewConfigGroup()
+	addValue(applicationGroup, channelconfig.CapabilitiesValue(map[string]bool{"V2_0": true}))
+	err := encoder.AddPolicies(applicationGroup, map[string]*genesisconfig.Policy{
+		channelconfig.ReadersPolicyKey: {Type: encoder.ImplicitMetaPolicyType, Rule: "ANY Readers"},
+		channelconfig.WritersPolicyKey: {Type: encoder.ImplicitMetaPolicyType, Rule: "ANY Writers"},
+		channelconfig.AdminsPolicyKey:  {Type: encoder.ImplicitMetaPolicyType, Rule: "MAJORITY Admins"},
+		"Endorsement":                  {Type: encoder.ImplicitMetaPolicyType, Rule: "MAJORITY Endorsement"},
+		"LifecycleEndorsement":         {Type: encoder.ImplicitMetaPolicyType, Rule: "MAJORITY Endorsement"},
+	}, channelconfig.AdminsPolicyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	mspIDs := make([]string, 0, len(msps))
+	for mspID := range msps {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+
+	for _, mspID := range mspIDs {
+		orgGroup, err := newOrgGroup(mspID, msps[mspID])
+		if err != nil {
+			return nil, err
+		}
+		applicationGroup.Groups[mspID] = orgGroup
+	}
+	applicationGroup.ModPolicy = channelconfig.AdminsPolicyKey
+
+	channelGroup := protoutil.NewConfigGroup()
+	addValue(channelGroup, channelconfig.HashingAlgorithmValue())
+	addValue(channelGroup, channelconfig.BlockDataHashingStructureValue())
+	addValue(channelGroup, channelconfig.CapabilitiesValue(map[string]bool{"V2_0": true}))
+	err = encoder.AddPolicies(channelGroup, map[string]*genesisconfig.Policy{
+		channelconfig.ReadersPolicyKey: {Type: encoder.ImplicitMetaPolicyType, Rule: "ANY Readers"},
+		channelconfig.WritersPolicyKey: {Type: encoder.ImplicitMetaPolicyType, Rule: "ANY Writers"},
+		channelconfig.AdminsPolicyKey:  {Type: encoder.ImplicitMetaPolicyType, Rule: "MAJORITY Admins"},
+	}, channelconfig.AdminsPolicyKey)
+	if err != nil {
+		return nil, err
+	}
+	channelGroup.Groups[channelconfig.ApplicationGroupKey] = applicationGroup
+	channelGroup.ModPolicy = channelconfig.AdminsPolicyKey
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	if err != nil {
+		return nil, err
+	}
+	return channelconfig.NewBundle(ChannelID, &cb.Config{ChannelGroup: channelGroup}, cryptoProvider)
+}
+
+func newOrgGroup(mspID string, fabricConfig *mspprotos.FabricMSPConfig) (*cb.ConfigGroup, error) {
+	if fabricConfig == nil {
+		return nil, errors.Errorf("MSP config of %s is nil", mspID)
+	}
+	switch fabricConfig.Name {
+	case mspID:
+	case "":
+		fabricConfig = proto.Clone(fabricConfig).(*mspprotos.FabricMSPConfig)
+		fabricConfig.Name = mspID
+	default:
+		return nil, errors.Errorf("MSP config keyed by %s is named %s", mspID, fabricConfig.Name)
+	}
+
+	orgGroup := protoutil.NewConfigGroup()
+	addValue(orgGroup, channelconfig.MSPValue(&mspprotos.MSPConfig{
+		Type:   int32(msp.FABRIC),
+		Config: protoutil.MarshalOrPanic(fabricConfig),
+	}))
+	err := encoder.AddPolicies(orgGroup, map[string]*genesisconfig.Policy{
+		channelconfig.ReadersPolicyKey: {Type: encoder.SignaturePolicyType, Rule: "OR('" + mspID + ".member')"},
+		channelconfig.WritersPolicyKey: {Type: encoder.SignaturePolicyType, Rule: "OR('" + mspID + ".member')"},
+		channelconfig.AdminsPolicyKey:  {Type: encoder.SignaturePolicyType, Rule: "OR('" + mspID + ".admin')"},
+		"Endorsement":                  {Type: encoder.SignaturePolicyType, Rule: "OR('" + mspID + ".member')"},
+	}, channelconfig.AdminsPolicyKey)
+	if err != nil {
+		return nil, err
+	}
+	orgGroup.ModPolicy = channelconfig.AdminsPolicyKey
+	return orgGroup, nil
+}
+
+func addValue(group *cb.ConfigGroup, value channelconfig.ConfigValue) {
+	group.Values[value.Key()] = &cb.ConfigValue{
+		Value:     protoutil.MarshalOrPanic(value.Value()),
+		ModPolicy: channelconfig.AdminsPolicyKey,
+	}
+}
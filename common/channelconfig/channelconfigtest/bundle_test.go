@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfigtest_test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/channelconfig/channelconfigtest"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestBundle(t *testing.T) {
+	mspConfig, err := msp.GetVerifyingMspConfig(configtest.GetDevMspDir(), "SampleOrg", "bccsp")
+	require.NoError(t, err)
+	fabricConfig := &mspprotos.FabricMSPConfig{}
+	require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricConfig))
+	unnamed := proto.Clone(fabricConfig).(*mspprotos.FabricMSPConfig)
+	unnamed.Name = ""
+
+	bundle, err := channelconfigtest.NewTestBundle(map[string]*mspprotos.FabricMSPConfig{
+		"SampleOrg": fabricConfig,
+		"OtherOrg":  unnamed,
+	})
+	require.NoError(t, err)
+	require.Empty(t, unnamed.Name)
+
+	msps, err := bundle.MSPManager().GetMSPs()
+	require.NoError(t, err)
+	require.Len(t, msps, 2)
+	require.Contains(t, msps, "OtherOrg")
+
+	ac, ok := bundle.ApplicationConfig()
+	require.True(t, ok)
+	require.Equal(t, "SampleOrg", ac.Organizations()["SampleOrg"].MSPID())
+	_, ok = bundle.OrdererConfig()
+	require.False(t, ok)
+
+	bs := channelconfig.NewBundleSource(bundle)
+	_, ok = bs.OrgPolicyManager(channelconfig.ApplicationGroupKey, "SampleOrg")
+	require.True(t, ok)
+
+	_, err = channelconfigtest.NewTestBundle(map[string]*mspprotos.FabricMSPConfig{"OtherOrg": fabricConfig})
+	require.EqualError(t, err, "MSP config keyed by OtherOrg is named SampleOrg")
+}
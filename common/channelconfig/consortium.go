@@ -49,13 +49,18 @@ func NewConsortiumConfig(consortiumGroup *cb.ConfigGroup, mspConfig *MSPConfigHa
 	return cc, nil
 }
 
-// Organizations returns the set of organizations in the consortium
+// Organizations returns a copy of the set of organizations in the consortium
 func (cc *ConsortiumConfig) Organizations() map[string]Org {
-	return cc.orgs
+	orgs := make(map[string]Org, len(cc.orgs))
+	for name, org := range cc.orgs {
+		orgs[name] = org
+	}
+	return orgs
 }
 
 // CreationPolicy returns the policy structure used to validate
-// the channel creation
+// the channel creation.  The returned message is shared with the config and
+// must not be modified.
 func (cc *ConsortiumConfig) ChannelCreationPolicy() *cb.Policy {
 	return cc.protos.ChannelCreationPolicy
 }
@@ -35,7 +35,11 @@ func NewConsortiumsConfig(consortiumsGroup *cb.ConfigGroup, mspConfig *MSPConfig
 	return cc, nil
 }
 
-// Consortiums returns a map of the current consortiums
+// Consortiums returns a copy of the map of the current consortiums
 func (cc *ConsortiumsConfig) Consortiums() map[string]Consortium {
-	return cc.consortiums
+	consortiums := make(map[string]Consortium, len(cc.consortiums))
+	for name, consortium := range cc.consortiums {
+		consortiums[name] = consortium
+	}
+	return consortiums
 }
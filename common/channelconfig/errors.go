@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBundleSourceNotInitialized is returned by BundleSource methods when no
+// bundle has been stored yet, e.g. because the BundleSource is a zero value
+// rather than one created via NewBundleSource.
+var ErrBundleSourceNotInitialized = errors.New("bundle source has not been initialized with a bundle")
+
+// ErrNoPreviousBundle is returned by BundleSource.Rollback when there is no
+// previous bundle to restore, i.e. only the initial bundle has been stored.
+var ErrNoPreviousBundle = errors.New("bundle source has no previous bundle")
+
+// ErrNoConsortiumsConfig is returned when consortiums are looked up on a
+// channel whose config has no Consortiums group, i.e. on a channel other than
+// an orderer system channel.
+var ErrNoConsortiumsConfig = errors.New("channel config does not contain a consortiums config")
+
+// ErrNoOrdererConfig is returned when orderer specific config is looked up on
+// a channel whose config has no Orderer group.
+var ErrNoOrdererConfig = errors.New("channel config does not contain an orderer config")
+
+// ErrPolicyManagerDisabled is returned by the evaluation of any policy of a
+// bundle built WithoutPolicyManager.
+var ErrPolicyManagerDisabled = errors.New("bundle was built without a policy manager")
+
+// PolicyNotFoundError is returned when a policy is evaluated which is not
+// defined in the channel config.
+type PolicyNotFoundError struct {
+	PolicyName string
+}
+
+func (e *PolicyNotFoundError) Error() string {
+	return fmt.Sprintf("policy %s not found", e.PolicyName)
+}
+
+// PolicyDeniedError is returned when a set of signatures fails to satisfy a
+// policy defined in the channel config.
+type PolicyDeniedError struct {
+	PolicyName string
+	Err        error
+}
+
+func (e *PolicyDeniedError) Error() string {
+	return fmt.Sprintf("policy %s not satisfied: %s", e.PolicyName, e.Err)
+}
+
+// Unwrap returns the error returned by the policy evaluation.
+func (e *PolicyDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// MalformedConfigError is returned when a bundle cannot be built because the
+// config is not well formed, e.g. because a value cannot be unmarshaled or a
+// required element is missing.
+type MalformedConfigError struct {
+	Err error
+}
+
+func (e *MalformedConfigError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error describing why the config is malformed.
+func (e *MalformedConfigError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedCapabilityError is returned when a config enables capabilities
+// which are not supported, either by this binary or in combination with the
+// other capabilities of the config.
+type UnsupportedCapabilityError struct {
+	Err error
+}
+
+func (e *UnsupportedCapabilityError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error describing the unsupported capability.
+func (e *UnsupportedCapabilityError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownMSPError is returned when an org of the config references an MSP ID
+// for which the config defines no MSP.
+type UnknownMSPError struct {
+	OrgName string
+	MSPID   string
+}
+
+func (e *UnknownMSPError) Error() string {
+	return fmt.Sprintf("organization %q references unknown MSPID %q", e.OrgName, e.MSPID)
+}
+
+// DanglingPolicyError is returned when an element of the config references a
+// policy which is not defined.  Referrer describes the referencing element,
+// e.g. "mod_policy of group /Channel/Application/Org1" or "ACL peer/Propose".
+type DanglingPolicyError struct {
+	Referrer   string
+	PolicyName string
+}
+
+func (e *DanglingPolicyError) Error() string {
+	return fmt.Sprintf("%s references undefined policy %q", e.Referrer, e.PolicyName)
+}
+
+// DuplicateMSPIDError is returned when differently named orgs of the config
+// claim the same MSP ID, which would allow each of them to act as the other.
+// OrgNames are sorted.
+type DuplicateMSPIDError struct {
+	MSPID    string
+	OrgNames []string
+}
+
+func (e *DuplicateMSPIDError) Error() string {
+	return fmt.Sprintf("MSPID %q is claimed by multiple organizations: %s", e.MSPID, strings.Join(e.OrgNames, ", "))
+}
+
+// MSPSetupError is returned when the MSP of an org cannot be set up from its
+// definition, e.g. because a CA certificate or the OU configuration is
+// malformed.  Bundles report it wrapped in a *MalformedConfigError.
+type MSPSetupError struct {
+	mspID string
+	group string
+	err   error
+}
+
+// MSPID returns the ID of the MSP which could not be set up, or the empty
+// string if the definition does not name the MSP.
+func (e *MSPSetupError) MSPID() string {
+	return e.mspID
+}
+
+// Group returns the channel group defining the MSP, e.g. Application, or the
+// empty string if it is not known.
+func (e *MSPSetupError) Group() string {
+	return e.group
+}
+
+func (e *MSPSetupError) Error() string {
+	if e.group == "" {
+		return fmt.Sprintf("failed to setup MSP %q: %s", e.mspID, e.err)
+	}
+	return fmt.Sprintf("failed to setup MSP %q in group %q: %s", e.mspID, e.group, e.err)
+}
+
+// Unwrap returns the error of the MSP setup.
+func (e *MSPSetupError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import "github.com/hyperledger/fabric/common/metrics"
+
+var (
+	bundleSourceUpdatesApplied = metrics.CounterOpts{
+		Namespace:    "channelconfig",
+		Subsystem:    "bundle_source",
+		Name:         "updates_applied",
+		Help:         "The number of bundles stored by a bundle source.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	bundleSourceSequence = metrics.GaugeOpts{
+		Namespace:    "channelconfig",
+		Subsystem:    "bundle_source",
+		Name:         "sequence",
+		Help:         "The sequence number of the current bundle of a bundle source.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	bundleSourceListenerDuration = metrics.HistogramOpts{
+		Namespace:    "channelconfig",
+		Subsystem:    "bundle_source",
+		Name:         "listener_duration",
+		Help:         "The time spent invoking the callbacks and update listeners of a bundle source in seconds.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+)
+
+// Metrics contains the metrics emitted by a BundleSource.
+type Metrics struct {
+	UpdatesApplied   metrics.Counter
+	Sequence         metrics.Gauge
+	ListenerDuration metrics.Histogram
+}
+
+// NewMetrics creates the metrics emitted by a BundleSource from the given
+// provider.
+func NewMetrics(p metrics.Provider) *Metrics {
+	return &Metrics{
+		UpdatesApplied:   p.NewCounter(bundleSourceUpdatesApplied),
+		Sequence:         p.NewGauge(bundleSourceSequence),
+		ListenerDuration: p.NewHistogram(bundleSourceListenerDuration),
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSourceMetrics(t *testing.T) {
+	fakeCounter := &metricsfakes.Counter{}
+	fakeCounter.WithReturns(fakeCounter)
+	fakeGauge := &metricsfakes.Gauge{}
+	fakeGauge.WithReturns(fakeGauge)
+	fakeHistogram := &metricsfakes.Histogram{}
+	fakeHistogram.WithReturns(fakeHistogram)
+
+	provider := &metricsfakes.Provider{}
+	provider.NewCounterReturns(fakeCounter)
+	provider.NewGaugeReturns(fakeGauge)
+	provider.NewHistogramReturns(fakeHistogram)
+
+	var callbacks int
+	bundle := newTestBundle(t, genesisconfig.SampleDevModeSoloProfile)
+	bs := channelconfig.NewBundleSourceWithOptions(
+		bundle,
+		channelconfig.WithMetricsProvider(provider),
+		channelconfig.WithCallbacks(func(*channelconfig.Bundle) { callbacks++ }),
+	)
+	bs.Update(bundle)
+	require.Equal(t, 2, callbacks)
+
+	require.Equal(t, 2, fakeCounter.AddCallCount())
+	require.Equal(t, float64(1), fakeCounter.AddArgsForCall(1))
+	require.Equal(t, []string{"channel", "testchannel"}, fakeCounter.WithArgsForCall(1))
+
+	require.Equal(t, 2, fakeGauge.SetCallCount())
+	require.Equal(t, float64(1), fakeGauge.SetArgsForCall(0))
+	require.Equal(t, float64(2), fakeGauge.SetArgsForCall(1))
+	require.Equal(t, []string{"channel", "testchannel"}, fakeGauge.WithArgsForCall(1))
+
+	require.Equal(t, 2, fakeHistogram.ObserveCallCount())
+	require.Equal(t, []string{"channel", "testchannel"}, fakeHistogram.WithArgsForCall(1))
+
+	t.Run("NilProvider", func(t *testing.T) {
+		bs := channelconfig.NewBundleSourceWithOptions(bundle, channelconfig.WithMetricsProvider(nil))
+		bs.Update(bundle)
+		require.Equal(t, uint64(2), bs.Sequence())
+	})
+}
@@ -74,7 +74,7 @@ func (bh *MSPConfigHandler) ProposeMSP(mspConfig *mspprotos.MSPConfig) (msp.MSP,
 	// set it up
 	err = theMsp.Setup(mspConfig)
 	if err != nil {
-		return nil, errors.WithMessage(err, "setting up the MSP manager failed")
+		return nil, &MSPSetupError{mspID: mspConfigName(mspConfig), err: err}
 	}
 
 	// add the MSP to the map of pending MSPs
@@ -95,6 +95,43 @@ func (bh *MSPConfigHandler) ProposeMSP(mspConfig *mspprotos.MSPConfig) (msp.MSP,
 	return theMsp, nil
 }
 
+// mspConfigName returns the MSP ID the MSP definition declares, or the empty
+// string if the definition cannot be unmarshaled.
+func mspConfigName(mspConfig *mspprotos.MSPConfig) string {
+	switch mspConfig.Type {
+	case int32(msp.FABRIC):
+		fabricConfig := &mspprotos.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err == nil {
+			return fabricConfig.Name
+		}
+	case int32(msp.IDEMIX):
+		idemixConfig := &mspprotos.IdemixMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, idemixConfig); err == nil {
+			return idemixConfig.Name
+		}
+	}
+	return ""
+}
+
+// withMSPSetupGroup records the group in the error if it is an
+// *MSPSetupError, which the group constructors return unwrapped.
+func withMSPSetupGroup(err error, group string) error {
+	if setupErr, ok := err.(*MSPSetupError); ok {
+		setupErr.group = group
+	}
+	return err
+}
+
+// malformedChannelConfigError classifies the error of NewChannelConfig as a
+// *MalformedConfigError, which wraps the *MSPSetupError if an MSP could not be
+// set up, and the error with the given message otherwise.
+func malformedChannelConfigError(err error, message string) *MalformedConfigError {
+	if setupErr, ok := errors.Cause(err).(*MSPSetupError); ok {
+		return &MalformedConfigError{Err: setupErr}
+	}
+	return &MalformedConfigError{Err: errors.Wrap(err, message)}
+}
+
 func (bh *MSPConfigHandler) CreateMSPManager() (msp.MSPManager, error) {
 	mspList := make([]msp.MSP, len(bh.idMap))
 	i := 0
@@ -14,6 +14,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric/core/config/configtest"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -64,4 +65,15 @@ func TestMSPConfigFailure(t *testing.T) {
 		_, err := mspCH.ProposeMSP(&mspprotos.MSPConfig{Type: int32(10)})
 		require.Error(t, err)
 	})
+
+	t.Run("Bad root cert", func(t *testing.T) {
+		_, err := mspCH.ProposeMSP(&mspprotos.MSPConfig{
+			Type:   int32(msp.FABRIC),
+			Config: protoutil.MarshalOrPanic(&mspprotos.FabricMSPConfig{Name: "Org2MSP", RootCerts: [][]byte{[]byte("garbage")}}),
+		})
+		require.IsType(t, &MSPSetupError{}, err)
+		require.Equal(t, "Org2MSP", err.(*MSPSetupError).MSPID())
+		require.Empty(t, err.(*MSPSetupError).Group())
+		require.Regexp(t, `^failed to setup MSP "Org2MSP": `, err.Error())
+	})
 }
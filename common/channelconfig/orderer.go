@@ -74,9 +74,9 @@ type OrdererOrgConfig struct {
 	name   string
 }
 
-// Endpoints returns the set of addresses this ordering org exposes as orderers
+// Endpoints returns a copy of the set of addresses this ordering org exposes as orderers
 func (oc *OrdererOrgConfig) Endpoints() []string {
-	return oc.protos.Endpoints.Addresses
+	return copyStrings(oc.protos.Endpoints.Addresses)
 }
 
 // NewOrdererOrgConfig returns an orderer org config built from the given ConfigGroup.
@@ -148,9 +148,9 @@ func (oc *OrdererConfig) ConsensusType() string {
 	return oc.protos.ConsensusType.Type
 }
 
-// ConsensusMetadata returns the metadata associated with the consensus type.
+// ConsensusMetadata returns a copy of the metadata associated with the consensus type.
 func (oc *OrdererConfig) ConsensusMetadata() []byte {
-	return oc.protos.ConsensusType.Metadata
+	return copyBytes(oc.protos.ConsensusType.Metadata)
 }
 
 // ConsensusState return the consensus type state.
@@ -159,6 +159,7 @@ func (oc *OrdererConfig) ConsensusState() ab.ConsensusType_State {
 }
 
 // BatchSize returns the maximum number of messages to include in a block.
+// The returned message is shared with the config and must not be modified.
 func (oc *OrdererConfig) BatchSize() *ab.BatchSize {
 	return oc.protos.BatchSize
 }
@@ -172,17 +173,21 @@ func (oc *OrdererConfig) BatchTimeout() time.Duration {
 // Kafka brokers, i.e. this is not necessarily the entire set of Kafka brokers
 // used for ordering.
 func (oc *OrdererConfig) KafkaBrokers() []string {
-	return oc.protos.KafkaBrokers.Brokers
+	return copyStrings(oc.protos.KafkaBrokers.Brokers)
 }
 
 // MaxChannelsCount returns the maximum count of channels this orderer supports.
 func (oc *OrdererConfig) MaxChannelsCount() uint64 {
-	return oc.protos.ChannelRestrictions.MaxCount
+	return oc.protos.ChannelRestrictions.GetMaxCount()
 }
 
-// Organizations returns a map of the orgs in the channel.
+// Organizations returns a copy of the map of the orgs in the channel.
 func (oc *OrdererConfig) Organizations() map[string]OrdererOrg {
-	return oc.orgs
+	orgs := make(map[string]OrdererOrg, len(oc.orgs))
+	for name, org := range oc.orgs {
+		orgs[name] = org
+	}
+	return orgs
 }
 
 // Capabilities returns the capabilities the ordering network has for this channel.
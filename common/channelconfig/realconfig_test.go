@@ -7,13 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package channelconfig_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
 	"github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/core/config/configtest"
 	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
 	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/protoutil"
 	"github.com/stretchr/testify/require"
 )
@@ -26,8 +31,24 @@ func TestWithRealConfigtx(t *testing.T) {
 	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
 	require.NoError(t, err)
 
-	_, err = channelconfig.NewBundleFromEnvelope(env, cryptoProvider)
+	bundle, err := channelconfig.NewBundleFromEnvelope(env, cryptoProvider)
 	require.NoError(t, err)
+	require.Equal(t, channelconfig.UnknownConfigBlockNumber, bundle.ConfigBlockNumber())
+	require.Nil(t, bundle.ConfigBlockHash())
+
+	gb.Header.Number = 7
+	bundle, err = channelconfig.NewBundleFromBlock(gb, cryptoProvider)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), bundle.ConfigBlockNumber())
+	require.Equal(t, protoutil.BlockHeaderHash(gb.Header), bundle.ConfigBlockHash())
+
+	clone, err := bundle.Clone()
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), clone.ConfigBlockNumber())
+	require.Equal(t, bundle.ConfigBlockHash(), clone.ConfigBlockHash())
+
+	_, err = channelconfig.NewBundleFromBlock(&common.Block{}, cryptoProvider)
+	require.EqualError(t, err, "block header cannot be nil")
 }
 
 func TestOrgSpecificOrdererEndpoints(t *testing.T) {
@@ -80,3 +101,279 @@ func TestOrgSpecificOrdererEndpoints(t *testing.T) {
 	})
 
 }
+
+func TestPolicyReferencesValidation(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	t.Run("Valid", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		cg, err := encoder.NewChannelGroup(conf)
+		require.NoError(t, err)
+
+		bundle, err := channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+		require.NoError(t, err)
+		require.NoError(t, bundle.ValidatePolicyReferences())
+	})
+
+	t.Run("DanglingModPolicy", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		cg, err := encoder.NewChannelGroup(conf)
+		require.NoError(t, err)
+		cg.Groups[channelconfig.ApplicationGroupKey].Groups["SampleOrg"].ModPolicy = "Missing"
+
+		_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+		require.NoError(t, err)
+
+		_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider, channelconfig.WithPolicyReferenceValidation())
+		require.EqualError(t, err, `mod_policy of group /Channel/Application/SampleOrg references undefined policy "Missing"`)
+		var danglingPolicy *channelconfig.DanglingPolicyError
+		require.True(t, errors.As(err, &danglingPolicy))
+		require.Equal(t, "Missing", danglingPolicy.PolicyName)
+	})
+
+	t.Run("DanglingAbsoluteModPolicy", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		cg, err := encoder.NewChannelGroup(conf)
+		require.NoError(t, err)
+		cg.Values[channelconfig.HashingAlgorithmKey].ModPolicy = "/Channel/Orderer/Missing"
+
+		_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+		require.NoError(t, err)
+
+		_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider, channelconfig.WithPolicyReferenceValidation())
+		require.EqualError(t, err, `mod_policy of value /Channel/HashingAlgorithm references undefined policy "/Channel/Orderer/Missing"`)
+	})
+
+	t.Run("DanglingACL", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		conf.Application.ACLs = map[string]string{"peer/Propose": "/Channel/Application/Missing"}
+		cg, err := encoder.NewChannelGroup(conf)
+		require.NoError(t, err)
+
+		_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+		require.NoError(t, err)
+
+		_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider, channelconfig.WithPolicyReferenceValidation())
+		require.EqualError(t, err, `ACL peer/Propose references undefined policy "/Channel/Application/Missing"`)
+		require.Equal(t, &channelconfig.DanglingPolicyError{Referrer: "ACL peer/Propose", PolicyName: "/Channel/Application/Missing"}, err)
+	})
+}
+
+func TestOrdererEndpointsValidation(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	conf.Orderer.Organizations[0].OrdererEndpoints = []string{"127.0.0.1:7050", "127.0.0.1"}
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+
+	_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+	require.NoError(t, err)
+
+	_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider, channelconfig.WithOrdererEndpointValidation())
+	require.EqualError(t, err, `invalid orderer endpoint "127.0.0.1" of org SampleOrg: address 127.0.0.1: missing port in address`)
+	var malformed *channelconfig.MalformedConfigError
+	require.True(t, errors.As(err, &malformed))
+
+	errs := channelconfig.ValidateConfig("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+	require.Len(t, errs, 1)
+	require.Equal(t, err, errs[0])
+}
+
+func TestUniqueMSPIDsValidation(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	ordererOrg := *conf.Orderer.Organizations[0]
+	ordererOrg.Name = "OrdererOrg"
+	conf.Orderer.Organizations[0] = &ordererOrg
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+
+	_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+	require.NoError(t, err)
+
+	_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider, channelconfig.WithUniqueMSPIDValidation())
+	require.EqualError(t, err, `MSPID "SampleOrg" is claimed by multiple organizations: OrdererOrg, SampleOrg`)
+	var duplicate *channelconfig.DuplicateMSPIDError
+	require.True(t, errors.As(err, &duplicate))
+
+	errs := channelconfig.ValidateConfig("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+	require.Len(t, errs, 1)
+	require.Equal(t, err, errs[0])
+}
+
+func TestMSPSetupError(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	mspValue := cg.Groups[channelconfig.ApplicationGroupKey].Groups["SampleOrg"].Values[channelconfig.MSPKey]
+	mspConfig := &mspprotos.MSPConfig{}
+	require.NoError(t, proto.Unmarshal(mspValue.Value, mspConfig))
+	fabricConfig := &mspprotos.FabricMSPConfig{}
+	require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricConfig))
+	fabricConfig.RootCerts = [][]byte{[]byte("garbage")}
+	mspConfig.Config = protoutil.MarshalOrPanic(fabricConfig)
+	mspValue.Value = protoutil.MarshalOrPanic(mspConfig)
+
+	_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+	require.Regexp(t, `^failed to setup MSP "SampleOrg" in group "Application": `, err.Error())
+	var malformed *channelconfig.MalformedConfigError
+	require.True(t, errors.As(err, &malformed))
+	var setupErr *channelconfig.MSPSetupError
+	require.True(t, errors.As(err, &setupErr))
+	require.Equal(t, "SampleOrg", setupErr.MSPID())
+	require.Equal(t, channelconfig.ApplicationGroupKey, setupErr.Group())
+
+	errs := channelconfig.ValidateConfig("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], err.Error())
+}
+
+func TestMalformedConfigError(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	cg.Values[channelconfig.HashingAlgorithmKey].Value = []byte("garbage")
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	_, err = channelconfig.NewBundle("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+	require.Error(t, err)
+	require.Regexp(t, "^initializing channelconfig failed", err.Error())
+
+	var malformed *channelconfig.MalformedConfigError
+	require.True(t, errors.As(err, &malformed))
+	require.NotNil(t, errors.Unwrap(err))
+}
+
+func TestValidateConfig(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	newChannelGroup := func(t *testing.T, conf *genesisconfig.Profile) *common.ConfigGroup {
+		cg, err := encoder.NewChannelGroup(conf)
+		require.NoError(t, err)
+		return cg
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		errs := channelconfig.ValidateConfig("foo", &common.Config{ChannelGroup: newChannelGroup(t, conf)}, cryptoProvider)
+		require.Empty(t, errs)
+	})
+
+	t.Run("NilConfig", func(t *testing.T) {
+		errs := channelconfig.ValidateConfig("foo", nil, cryptoProvider)
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs[0], "channelconfig Config cannot be nil")
+	})
+
+	t.Run("DanglingReferences", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+		conf.Application.ACLs = map[string]string{"peer/Propose": "/Channel/Application/Missing"}
+		cg := newChannelGroup(t, conf)
+		cg.Values[channelconfig.HashingAlgorithmKey].ModPolicy = "/Channel/Orderer/Missing"
+		cg.Groups[channelconfig.ApplicationGroupKey].Groups["SampleOrg"].ModPolicy = "Missing"
+		config := &common.Config{ChannelGroup: cg}
+
+		errs := channelconfig.ValidateConfig("foo", config, cryptoProvider)
+		require.Len(t, errs, 3)
+		require.EqualError(t, errs[0], `mod_policy of value /Channel/HashingAlgorithm references undefined policy "/Channel/Orderer/Missing"`)
+		require.EqualError(t, errs[1], `mod_policy of group /Channel/Application/SampleOrg references undefined policy "Missing"`)
+		require.EqualError(t, errs[2], `ACL peer/Propose references undefined policy "/Channel/Application/Missing"`)
+
+		_, err := channelconfig.NewBundle("foo", config, cryptoProvider, channelconfig.WithPolicyReferenceValidation())
+		require.Equal(t, errs[0], err)
+	})
+
+	t.Run("MalformedSections", func(t *testing.T) {
+		conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+		conf.Capabilities["V9_9"] = true
+		cg := newChannelGroup(t, conf)
+		cg.Values[channelconfig.HashingAlgorithmKey].Value = protoutil.MarshalOrPanic(&common.HashingAlgorithm{Name: "MD5"})
+		cg.Groups[channelconfig.ApplicationGroupKey].Groups["SampleOrg"].Values[channelconfig.MSPKey].Value = []byte("garbage")
+		cg.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.BatchTimeoutKey].Value = []byte("garbage")
+		// references are not checked while sections are malformed
+		cg.Groups[channelconfig.ConsortiumsGroupKey].ModPolicy = "Missing"
+
+		errs := channelconfig.ValidateConfig("foo", &common.Config{ChannelGroup: cg}, cryptoProvider)
+		require.Len(t, errs, 4)
+
+		var unsupported *channelconfig.UnsupportedCapabilityError
+		require.True(t, errors.As(errs[0], &unsupported))
+		require.EqualError(t, errs[0], "Channel capability V9_9 is required but not supported")
+
+		var malformed *channelconfig.MalformedConfigError
+		require.True(t, errors.As(errs[1], &malformed))
+		require.Contains(t, errs[1].Error(), "MD5")
+		require.True(t, errors.As(errs[2], &malformed))
+		require.Regexp(t, "^could not create channel Application sub-group config", errs[2].Error())
+		require.True(t, errors.As(errs[3], &malformed))
+		require.Regexp(t, "^could not create channel Orderer sub-group config", errs[3].Error())
+	})
+}
+
+type stubMSPManager struct {
+	msp.MSPManager
+}
+
+func TestWithMSPManagerFactory(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	config := &common.Config{ChannelGroup: cg}
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	reference, err := channelconfig.NewBundle("foo", config, cryptoProvider)
+	require.NoError(t, err)
+
+	var configs []*common.Config
+	factory := func(config *common.Config) (msp.MSPManager, error) {
+		configs = append(configs, config)
+		return &stubMSPManager{MSPManager: reference.MSPManager()}, nil
+	}
+	bundle, err := channelconfig.NewBundle("foo", config, cryptoProvider, channelconfig.WithMSPManagerFactory(factory))
+	require.NoError(t, err)
+	require.Equal(t, []*common.Config{config}, configs)
+	require.IsType(t, &stubMSPManager{}, bundle.MSPManager())
+
+	clone, err := bundle.Clone()
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	require.IsType(t, &stubMSPManager{}, clone.MSPManager())
+
+	_, err = channelconfig.NewBundle("foo", config, cryptoProvider, channelconfig.WithMSPManagerFactory(func(*common.Config) (msp.MSPManager, error) {
+		return nil, errors.New("factory-error")
+	}))
+	require.EqualError(t, err, "MSP manager factory failed: factory-error")
+}
+
+func TestMSPReferencesValidation(t *testing.T) {
+	conf := genesisconfig.Load(genesisconfig.SampleSingleMSPChannelProfile, configtest.GetDevConfigDir())
+	cg, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	config := &common.Config{ChannelGroup: cg}
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	factory := channelconfig.WithMSPManagerFactory(func(*common.Config) (msp.MSPManager, error) {
+		mspManager := msp.NewMSPManager()
+		return mspManager, mspManager.Setup(nil)
+	})
+
+	_, err = channelconfig.NewBundle("foo", config, cryptoProvider, factory)
+	require.NoError(t, err)
+
+	_, err = channelconfig.NewBundle("foo", config, cryptoProvider, factory, channelconfig.WithMSPReferenceValidation())
+	var unknown *channelconfig.UnknownMSPError
+	require.True(t, errors.As(err, &unknown))
+	require.Equal(t, "SampleOrg", unknown.MSPID)
+}
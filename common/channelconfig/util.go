@@ -240,7 +240,8 @@ func ACLValues(acls map[string]string) *StandardConfigValue {
 	}
 }
 
-// ValidateCapabilities validates whether the peer can meet the capabilities requirement in the given config block
+// ValidateCapabilities validates whether the peer can meet the capabilities requirement in the given config block.
+// If it cannot, an *UnsupportedCapabilityError is returned.
 func ValidateCapabilities(block *cb.Block, bccsp bccsp.BCCSP) error {
 	cc, err := extractChannelConfig(block, bccsp)
 	if err != nil {
@@ -248,11 +249,14 @@ func ValidateCapabilities(block *cb.Block, bccsp bccsp.BCCSP) error {
 	}
 	// Check the channel top-level capabilities
 	if err := cc.Capabilities().Supported(); err != nil {
-		return err
+		return &UnsupportedCapabilityError{Err: err}
 	}
 
 	// Check the application capabilities
-	return cc.ApplicationConfig().Capabilities().Supported()
+	if err := cc.ApplicationConfig().Capabilities().Supported(); err != nil {
+		return &UnsupportedCapabilityError{Err: err}
+	}
+	return nil
 }
 
 // ExtractMSPIDsForApplicationOrgs extracts MSPIDs for application organizations
@@ -329,3 +333,23 @@ func MarshalEtcdRaftMetadata(md *etcdraft.ConfigMetadata) ([]byte, error) {
 	}
 	return proto.Marshal(copyMd)
 }
+
+// copyStrings returns a copy of the given slice, preserving nil.
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	c := make([]string, len(s))
+	copy(c, s)
+	return c
+}
+
+// copyBytes returns a copy of the given slice, preserving nil.
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
@@ -296,6 +296,7 @@ func TestValidateCapabilities(t *testing.T) {
 	cfgBlock = createCfgBlockWithUnsupportedCapabilities(t)
 	err = ValidateCapabilities(cfgBlock, cryptoProvider)
 	require.EqualError(t, err, "Channel capability INCOMPATIBLE_CAPABILITIES is required but not supported")
+	require.IsType(t, &UnsupportedCapabilityError{}, err)
 }
 
 func TestExtractMSPIDsForApplicationOrgs(t *testing.T) {
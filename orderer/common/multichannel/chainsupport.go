@@ -138,7 +138,7 @@ func (cs *ChainSupport) ProposeConfigUpdate(configtx *cb.Envelope) (*cb.ConfigEn
 		return nil, err
 	}
 
-	bundle, err := cs.CreateBundle(cs.ChannelID(), env.Config)
+	bundle, err := cs.CreateProposedBundle(cs.ChannelID(), env.Config)
 	if err != nil {
 		return nil, err
 	}
@@ -12,9 +12,14 @@ import (
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	"github.com/hyperledger/fabric/orderer/common/types"
+	"github.com/hyperledger/fabric/protoutil"
 
 	"github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
 	msgprocessormocks "github.com/hyperledger/fabric/orderer/common/msgprocessor/mocks"
 	"github.com/hyperledger/fabric/orderer/common/multichannel/mocks"
 	"github.com/pkg/errors"
@@ -68,6 +73,87 @@ func TestConsensusMetadataValidation(t *testing.T) {
 	require.EqualError(t, err, "consensus metadata update for channel config update is invalid: bananas")
 }
 
+func TestProposeConfigUpdateValidation(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		require.NoError(t, proposeTestConfig(t, func(*common.ConfigGroup) {}))
+	})
+
+	t.Run("DanglingModPolicy", func(t *testing.T) {
+		err := proposeTestConfig(t, func(channelGroup *common.ConfigGroup) {
+			channelGroup.Groups["Orderer"].ModPolicy = "Missing"
+		})
+		require.IsType(t, &channelconfig.DanglingPolicyError{}, err)
+	})
+
+	t.Run("MalformedOrdererEndpoint", func(t *testing.T) {
+		setEndpoints := func(channelGroup *common.ConfigGroup) {
+			channelGroup.Groups["Orderer"].Groups["SampleOrg"].Values[channelconfig.EndpointsKey].Value = protoutil.MarshalOrPanic(&common.OrdererAddresses{
+				Addresses: []string{"localhost"},
+			})
+		}
+		err := proposeTestConfig(t, setEndpoints)
+		require.IsType(t, &channelconfig.MalformedConfigError{}, err)
+		require.Contains(t, err.Error(), `invalid orderer endpoint "localhost" of org SampleOrg`)
+
+		// a committed config with the same endpoint keeps loading
+		config := proposalTestConfig(t)
+		setEndpoints(config.ChannelGroup)
+		cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+		require.NoError(t, err)
+		_, err = (&configResources{bccsp: cryptoProvider}).CreateBundle("mychannel", config)
+		require.NoError(t, err)
+	})
+
+	t.Run("DuplicateMSPIDAcrossGroups", func(t *testing.T) {
+		err := proposeTestConfig(t, func(channelGroup *common.ConfigGroup) {
+			applicationGroup := channelGroup.Groups["Application"]
+			applicationGroup.Groups["OtherOrg"] = applicationGroup.Groups["SampleOrg"]
+			delete(applicationGroup.Groups, "SampleOrg")
+		})
+		require.Equal(t, &channelconfig.DuplicateMSPIDError{MSPID: "SampleOrg", OrgNames: []string{"OtherOrg", "SampleOrg"}}, err)
+	})
+}
+
+// proposalTestConfig returns the config of the SampleDevModeSolo profile,
+// which defines SampleOrg in all groups.
+func proposalTestConfig(t *testing.T) *common.Config {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	group, err := encoder.NewChannelGroup(conf)
+	require.NoError(t, err)
+	return &common.Config{ChannelGroup: group}
+}
+
+// proposeTestConfig proposes a config update to a chain support which results
+// in the config of proposalTestConfig, after the given function has modified
+// its channel group.
+func proposeTestConfig(t *testing.T, modify func(channelGroup *common.ConfigGroup)) error {
+	env := &common.ConfigEnvelope{Config: proposalTestConfig(t)}
+	modify(env.Config.ChannelGroup)
+
+	mockValidator := &mocks.ConfigTXValidator{}
+	mockValidator.ChannelIDReturns("mychannel")
+	mockValidator.ProposeConfigUpdateReturns(env, nil)
+	mockResources := &mocks.Resources{}
+	mockResources.ConfigtxValidatorReturns(mockValidator)
+	mockResources.OrdererConfigReturns(&mocks.OrdererConfig{}, true)
+
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	cs := &ChainSupport{
+		ledgerResources: &ledgerResources{
+			configResources: &configResources{
+				mutableResources: &mutableResourcesMock{Resources: mockResources},
+				bccsp:            cryptoProvider,
+			},
+		},
+		MetadataValidator: &msgprocessormocks.MetadataValidator{},
+		BCCSP:             cryptoProvider,
+	}
+
+	_, err = cs.ProposeConfigUpdate(&common.Envelope{})
+	return err
+}
+
 func TestNewOnboardingChainSupport(t *testing.T) {
 	mockResources := &mocks.Resources{}
 	mockValidator := &mocks.ConfigTXValidator{}
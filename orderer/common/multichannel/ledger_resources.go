@@ -16,6 +16,18 @@ import (
 	"github.com/pkg/errors"
 )
 
+// proposalBundleOptions returns the options enabling the checks of
+// channelconfig.NewBundle which are not performed by default and which every
+// config proposed to the orderer must pass.
+func proposalBundleOptions() []channelconfig.BundleOption {
+	return []channelconfig.BundleOption{
+		channelconfig.WithMSPReferenceValidation(),
+		channelconfig.WithPolicyReferenceValidation(),
+		channelconfig.WithOrdererEndpointValidation(),
+		channelconfig.WithUniqueMSPIDValidation(),
+	}
+}
+
 // checkResources makes sure that the channel config is compatible with this binary and logs sanity checks
 func checkResources(res channelconfig.Resources) error {
 	channelconfig.LogSanityChecks(res)
@@ -53,6 +65,13 @@ func (cr *configResources) CreateBundle(channelID string, config *common.Config)
 	return channelconfig.NewBundle(channelID, config, cr.bccsp)
 }
 
+// CreateProposedBundle is like CreateBundle, but additionally performs the
+// checks of proposalBundleOptions, which a proposed config update must pass
+// while a committed config need not.
+func (cr *configResources) CreateProposedBundle(channelID string, config *common.Config) (*channelconfig.Bundle, error) {
+	return channelconfig.NewBundle(channelID, config, cr.bccsp, proposalBundleOptions()...)
+}
+
 func (cr *configResources) Update(bndl *channelconfig.Bundle) {
 	checkResourcesOrPanic(bndl)
 	cr.mutableResources.Update(bndl)
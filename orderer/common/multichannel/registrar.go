@@ -646,9 +646,11 @@ func (r *Registrar) NewChannelConfig(envConfigUpdate *cb.Envelope) (channelconfi
 	return r.templator.NewChannelConfig(envConfigUpdate)
 }
 
-// CreateBundle calls channelconfig.NewBundle
+// CreateBundle calls channelconfig.NewBundle with the checks of
+// proposalBundleOptions, as the config of a channel to be created is proposed
+// rather than committed.
 func (r *Registrar) CreateBundle(channelID string, config *cb.Config) (channelconfig.Resources, error) {
-	return channelconfig.NewBundle(channelID, config, r.bccsp)
+	return channelconfig.NewBundle(channelID, config, r.bccsp, proposalBundleOptions()...)
 }
 
 // ChannelList returns a slice of ChannelInfoShort containing all application channels (excluding the system